@@ -0,0 +1,302 @@
+// Package auth obtains OAuth2 access tokens with the client credentials,
+// device authorization and refresh token grants, caches them on disk and
+// refreshes them before they expire.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Flows supported by a Provider.
+const (
+	ClientCredentials = "client_credentials"
+	Device            = "device"
+	RefreshToken      = "refresh_token"
+)
+
+const (
+	// expirySkew is how long before its expiry a token is refreshed.
+	expirySkew = 30 * time.Second
+	// defaultLifetime is the lifetime of tokens issued without expires_in.
+	defaultLifetime = time.Hour
+)
+
+// Config describes how to obtain tokens for an endpoint.
+type Config struct {
+	// Flow is client_credentials, device or refresh_token.
+	Flow string `yaml:"flow"`
+	// Issuer is an OIDC issuer URL, used to discover the endpoints that are not set.
+	Issuer        string   `yaml:"issuer"`
+	TokenURL      string   `yaml:"token_url"`
+	DeviceAuthURL string   `yaml:"device_auth_url"`
+	ClientID      string   `yaml:"client_id"`
+	ClientSecret  string   `yaml:"client_secret"`
+	Scopes        []string `yaml:"scopes"`
+	// Audience is sent as the audience parameter, required by some providers such as Auth0.
+	Audience string `yaml:"audience"`
+	// RefreshToken is the initial refresh token of the refresh_token flow.
+	RefreshToken string `yaml:"refresh_token"`
+}
+
+// Validate checks that the configuration names a supported flow and its settings.
+func (c *Config) Validate() error {
+	switch c.Flow {
+	case ClientCredentials, Device:
+	case RefreshToken:
+		if c.RefreshToken == "" {
+			return fmt.Errorf("auth: the refresh_token flow needs a refresh_token")
+		}
+	default:
+		return fmt.Errorf("auth: unknown flow %q (use: %s, %s, %s)", c.Flow, ClientCredentials, Device, RefreshToken)
+	}
+
+	if c.ClientID == "" {
+		return fmt.Errorf("auth: client_id is required")
+	}
+
+	if c.Issuer == "" && c.TokenURL == "" {
+		return fmt.Errorf("auth: issuer or token_url is required")
+	}
+
+	return nil
+}
+
+// Token is an access token and what is needed to renew it.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"`
+}
+
+// valid reports whether the token can be used at now without refreshing.
+func (t *Token) valid(now time.Time) bool {
+	return t != nil && t.AccessToken != "" && now.Add(expirySkew).Before(t.Expiry)
+}
+
+// header returns the Authorization header value.
+func (t *Token) header() string {
+	typ := t.TokenType
+	if typ == "" || strings.EqualFold(typ, "bearer") {
+		typ = "Bearer"
+	}
+
+	return typ + " " + t.AccessToken
+}
+
+// Provider authorizes requests with tokens from a flow. It is safe for concurrent use.
+type Provider struct {
+	cfg        Config
+	httpClient *http.Client
+	cacheDir   string
+	prompt     io.Writer
+	now        func() time.Time
+	wait       func(ctx context.Context, d time.Duration) error
+
+	// mu guards the fields below. It is not held while talking to the
+	// authorization server; renewing is closed when a renewal finishes.
+	mu         sync.Mutex
+	token      *Token
+	loaded     bool
+	renewing   chan struct{}
+	discovered *endpoints
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithCacheDir sets the directory where tokens are cached. An empty directory disables the cache.
+func WithCacheDir(dir string) Option {
+	return func(p *Provider) {
+		p.cacheDir = dir
+	}
+}
+
+// WithPrompt sets where the device flow asks the user to sign in. It defaults to os.Stderr.
+func WithPrompt(w io.Writer) Option {
+	return func(p *Provider) {
+		p.prompt = w
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to reach the authorization server.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(p *Provider) {
+		p.httpClient = hc
+	}
+}
+
+// New creates a provider for cfg. Tokens are kept in memory only unless
+// WithCacheDir sets a cache directory.
+func New(cfg *Config, opts ...Option) (*Provider, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	p := &Provider{
+		cfg:        *cfg,
+		httpClient: http.DefaultClient,
+		prompt:     os.Stderr,
+		now:        time.Now,
+		wait:       sleep,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p, nil
+}
+
+// Authorize sets the Authorization header of req, obtaining or refreshing the token first if needed.
+func (p *Provider) Authorize(ctx context.Context, req *http.Request) error {
+	t, err := p.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", t.header())
+
+	return nil
+}
+
+// Token returns a valid token from memory, the cache, a refresh or the flow,
+// in that order. Concurrent callers share a single renewal.
+func (p *Provider) Token(ctx context.Context) (*Token, error) {
+	for {
+		p.mu.Lock()
+
+		if !p.loaded {
+			p.token = p.loadCache()
+			p.loaded = true
+		}
+
+		if t := p.token; t.valid(p.now()) {
+			p.mu.Unlock()
+
+			return t, nil
+		}
+
+		if ch := p.renewing; ch != nil {
+			p.mu.Unlock()
+
+			select {
+			case <-ch:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err() //nolint:wrapcheck // context errors are returned as is
+			}
+		}
+
+		ch := make(chan struct{})
+		p.renewing = ch
+		current := p.token
+		p.mu.Unlock()
+
+		t, err := p.renew(ctx, current)
+
+		p.mu.Lock()
+		p.renewing = nil
+
+		if err == nil {
+			p.token = t
+			p.saveCache(t)
+		}
+
+		close(ch)
+		p.mu.Unlock()
+
+		return t, err
+	}
+}
+
+// Invalidate drops the access token after the server rejected it, keeping
+// the refresh token so that the next request renews it.
+func (p *Provider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token == nil {
+		return
+	}
+
+	t := *p.token
+	t.AccessToken = ""
+	p.token = &t
+	p.saveCache(&t)
+}
+
+// Login discards any cached token and runs the flow.
+func (p *Provider) Login(ctx context.Context) (*Token, error) {
+	t, err := p.runFlow(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.token = t
+	p.loaded = true
+	p.saveCache(t)
+
+	return t, nil
+}
+
+// Logout forgets the token and removes it from the cache.
+func (p *Provider) Logout() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.token = nil
+	p.loaded = true
+
+	return p.removeCache()
+}
+
+// renew refreshes current when it has a refresh token, and falls back to the
+// flow when there is none or the refresh is rejected.
+func (p *Provider) renew(ctx context.Context, current *Token) (*Token, error) {
+	refresh := p.cfg.RefreshToken
+	if current != nil && current.RefreshToken != "" {
+		refresh = current.RefreshToken
+	}
+
+	if refresh != "" {
+		t, err := p.refresh(ctx, refresh)
+		if err == nil || p.cfg.Flow == RefreshToken {
+			return t, err
+		}
+	}
+
+	return p.runFlow(ctx)
+}
+
+func (p *Provider) runFlow(ctx context.Context) (*Token, error) {
+	switch p.cfg.Flow {
+	case ClientCredentials:
+		return p.clientCredentials(ctx)
+	case Device:
+		return p.deviceFlow(ctx)
+	default:
+		return p.refresh(ctx, p.cfg.RefreshToken)
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // context errors are returned as is
+	case <-timer.C:
+		return nil
+	}
+}
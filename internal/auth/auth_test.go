@@ -0,0 +1,475 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tokenServer is an authorization server stub with discovery, a device
+// authorization endpoint and a token endpoint. Device codes are approved
+// after pending polls, and each issued access token is numbered.
+type tokenServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	pending  int
+	issued   int
+	grants   []string
+	lastForm map[string]string
+	basic    string
+}
+
+func newTokenServer(t *testing.T) *tokenServer {
+	t.Helper()
+
+	s := &tokenServer{}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                        s.URL,
+			"token_endpoint":                s.URL + "/token",
+			"device_authorization_endpoint": s.URL + "/device",
+		})
+	})
+
+	mux.HandleFunc("/device", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"device_code":      "dev-123",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": s.URL + "/activate",
+			"expires_in":       600,
+			"interval":         1,
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		_ = r.ParseForm()
+
+		s.lastForm = make(map[string]string)
+		for k := range r.PostForm {
+			s.lastForm[k] = r.PostForm.Get(k)
+		}
+
+		if user, pass, ok := r.BasicAuth(); ok {
+			s.basic = user + ":" + pass
+		}
+
+		grant := r.PostForm.Get("grant_type")
+		s.grants = append(s.grants, grant)
+
+		switch grant {
+		case deviceCodeGrant:
+			if s.pending > 0 {
+				s.pending--
+
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"authorization_pending"}`))
+
+				return
+			}
+		case "refresh_token":
+			if r.PostForm.Get("refresh_token") == "revoked" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"token revoked"}`))
+
+				return
+			}
+		}
+
+		s.issued++
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  fmt.Sprintf("access-%d", s.issued),
+			"token_type":    "bearer",
+			"refresh_token": fmt.Sprintf("refresh-%d", s.issued),
+			"expires_in":    3600,
+		})
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *tokenServer) grantLog() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.grants...)
+}
+
+// testProvider returns a provider with a fake clock and no waiting.
+func testProvider(t *testing.T, cfg *Config, opts ...Option) (*Provider, *time.Time) {
+	t.Helper()
+
+	p, err := New(cfg, append([]Option{WithCacheDir(t.TempDir()), WithPrompt(&bytes.Buffer{})}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	p.wait = func(ctx context.Context, _ time.Duration) error { return ctx.Err() }
+
+	return p, &now
+}
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"valid", Config{Flow: ClientCredentials, TokenURL: "http://x/token", ClientID: "c"}, ""},
+		{"issuer only", Config{Flow: Device, Issuer: "http://x", ClientID: "c"}, ""},
+		{"unknown flow", Config{Flow: "password", TokenURL: "http://x/token", ClientID: "c"}, "unknown flow"},
+		{"no client", Config{Flow: ClientCredentials, TokenURL: "http://x/token"}, "client_id"},
+		{"no endpoint", Config{Flow: ClientCredentials, ClientID: "c"}, "issuer or token_url"},
+		{"no refresh token", Config{Flow: RefreshToken, TokenURL: "http://x/token", ClientID: "c"}, "needs a refresh_token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.cfg.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_ClientCredentials(t *testing.T) {
+	t.Parallel()
+
+	srv := newTokenServer(t)
+	p, _ := testProvider(t, &Config{
+		Flow:         ClientCredentials,
+		Issuer:       srv.URL,
+		ClientID:     "iris",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+		Audience:     "api",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	if err := p.Authorize(context.Background(), req); err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+
+	if got := req.Header.Get("Authorization"); got != "Bearer access-1" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer access-1")
+	}
+
+	if srv.basic != "iris:secret" {
+		t.Errorf("basic auth = %q, want %q", srv.basic, "iris:secret")
+	}
+
+	if srv.lastForm["scope"] != "read write" || srv.lastForm["audience"] != "api" {
+		t.Errorf("form = %v", srv.lastForm)
+	}
+
+	if _, ok := srv.lastForm["client_id"]; ok {
+		t.Error("client_id sent in the form along with basic auth")
+	}
+
+	// The token is reused until it is about to expire
+	if err := p.Authorize(context.Background(), req); err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+
+	if got := len(srv.grantLog()); got != 1 {
+		t.Errorf("token requests = %d, want 1", got)
+	}
+}
+
+func TestProvider_RefreshBeforeExpiry(t *testing.T) {
+	t.Parallel()
+
+	srv := newTokenServer(t)
+	p, now := testProvider(t, &Config{Flow: ClientCredentials, TokenURL: srv.URL + "/token", ClientID: "iris"})
+
+	if _, err := p.Token(context.Background()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	// Within the expiry skew of the one hour lifetime
+	*now = now.Add(time.Hour - expirySkew/2)
+
+	tok, err := p.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	if tok.AccessToken != "access-2" || tok.RefreshToken != "refresh-2" {
+		t.Errorf("token = %+v, want the second token", tok)
+	}
+
+	want := []string{"client_credentials", "refresh_token"}
+	if got := srv.grantLog(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("grants = %v, want %v", got, want)
+	}
+
+	if srv.lastForm["refresh_token"] != "refresh-1" || srv.lastForm["client_id"] != "iris" {
+		t.Errorf("refresh form = %v", srv.lastForm)
+	}
+}
+
+func TestProvider_RefreshRejected(t *testing.T) {
+	t.Parallel()
+
+	srv := newTokenServer(t)
+
+	t.Run("falls back to the flow", func(t *testing.T) {
+		t.Parallel()
+
+		p, _ := testProvider(t, &Config{Flow: ClientCredentials, TokenURL: srv.URL + "/token", ClientID: "iris"})
+		p.token = &Token{AccessToken: "old", RefreshToken: "revoked", Expiry: p.now().Add(-time.Minute)}
+
+		tok, err := p.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+
+		if tok.AccessToken == "old" {
+			t.Error("expired token returned")
+		}
+	})
+
+	t.Run("refresh token flow fails", func(t *testing.T) {
+		t.Parallel()
+
+		p, _ := testProvider(t, &Config{Flow: RefreshToken, TokenURL: srv.URL + "/token", ClientID: "iris", RefreshToken: "revoked"})
+
+		_, err := p.Token(context.Background())
+
+		var oe *oauthError
+		if !errors.As(err, &oe) || oe.Code != "invalid_grant" {
+			t.Fatalf("Token() error = %v, want invalid_grant", err)
+		}
+	})
+}
+
+func TestProvider_RefreshTokenFlow(t *testing.T) {
+	t.Parallel()
+
+	srv := newTokenServer(t)
+	p, _ := testProvider(t, &Config{Flow: RefreshToken, TokenURL: srv.URL + "/token", ClientID: "iris", RefreshToken: "initial"})
+
+	tok, err := p.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	if tok.AccessToken != "access-1" {
+		t.Errorf("AccessToken = %q, want %q", tok.AccessToken, "access-1")
+	}
+
+	if srv.lastForm["refresh_token"] != "initial" {
+		t.Errorf("refresh_token = %q, want %q", srv.lastForm["refresh_token"], "initial")
+	}
+}
+
+func TestProvider_DeviceFlow(t *testing.T) {
+	t.Parallel()
+
+	srv := newTokenServer(t)
+	srv.pending = 2
+
+	var prompt bytes.Buffer
+
+	p, _ := testProvider(t, &Config{Flow: Device, Issuer: srv.URL, ClientID: "iris"}, WithPrompt(&prompt))
+
+	var waits int
+
+	p.wait = func(context.Context, time.Duration) error {
+		waits++
+
+		return nil
+	}
+
+	tok, err := p.Login(context.Background())
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	if tok.AccessToken != "access-1" {
+		t.Errorf("AccessToken = %q, want %q", tok.AccessToken, "access-1")
+	}
+
+	if waits != 3 {
+		t.Errorf("polls = %d, want 3", waits)
+	}
+
+	if !strings.Contains(prompt.String(), "ABCD-EFGH") || !strings.Contains(prompt.String(), srv.URL+"/activate") {
+		t.Errorf("prompt = %q", prompt.String())
+	}
+
+	if srv.lastForm["device_code"] != "dev-123" {
+		t.Errorf("device_code = %q, want %q", srv.lastForm["device_code"], "dev-123")
+	}
+}
+
+func TestProvider_Cache(t *testing.T) {
+	t.Parallel()
+
+	srv := newTokenServer(t)
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"token url", Config{Flow: ClientCredentials, TokenURL: srv.URL + "/token", ClientID: "iris"}},
+		// Discovery must not change the cache key
+		{"issuer only", Config{Flow: ClientCredentials, Issuer: srv.URL, ClientID: "issuer-only"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			first, _ := testProvider(t, &tt.cfg, WithCacheDir(dir))
+
+			tok, err := first.Token(context.Background())
+			if err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+
+			requests := len(srv.grantLog())
+
+			// A new provider with the same settings reads the cached token
+			second, _ := testProvider(t, &tt.cfg, WithCacheDir(dir))
+
+			cached, err := second.Token(context.Background())
+			if err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+
+			if cached.AccessToken != tok.AccessToken || len(srv.grantLog()) != requests {
+				t.Errorf("token = %q after %d requests, want the cached %q", cached.AccessToken, len(srv.grantLog())-requests, tok.AccessToken)
+			}
+
+			if err := second.Logout(); err != nil {
+				t.Fatalf("Logout() error = %v", err)
+			}
+
+			third, _ := testProvider(t, &tt.cfg, WithCacheDir(dir))
+			if third.loadCache() != nil {
+				t.Error("token still cached after Logout()")
+			}
+		})
+	}
+}
+
+func TestProvider_NoExpiry(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"forever","token_type":"bearer"}`))
+	}))
+	defer srv.Close()
+
+	p, now := testProvider(t, &Config{Flow: ClientCredentials, TokenURL: srv.URL, ClientID: "iris"})
+
+	tok, err := p.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	if want := now.Add(defaultLifetime); !tok.Expiry.Equal(want) {
+		t.Errorf("Expiry = %v, want %v", tok.Expiry, want)
+	}
+}
+
+func TestProvider_Invalidate(t *testing.T) {
+	t.Parallel()
+
+	srv := newTokenServer(t)
+	p, _ := testProvider(t, &Config{Flow: ClientCredentials, TokenURL: srv.URL + "/token", ClientID: "iris"})
+
+	if _, err := p.Token(context.Background()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	p.Invalidate()
+
+	if p.loadCache().valid(p.now()) {
+		t.Error("rejected token still valid in the cache")
+	}
+
+	tok, err := p.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	// The refresh token outlives the rejected access token
+	want := []string{"client_credentials", "refresh_token"}
+	if got := srv.grantLog(); tok.AccessToken != "access-2" || strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("token = %q after grants %v, want access-2 after %v", tok.AccessToken, got, want)
+	}
+}
+
+func TestProvider_ConcurrentRenewal(t *testing.T) {
+	t.Parallel()
+
+	srv := newTokenServer(t)
+	p, _ := testProvider(t, &Config{Flow: ClientCredentials, TokenURL: srv.URL + "/token", ClientID: "iris"})
+
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Go(func() {
+			if _, err := p.Token(context.Background()); err != nil {
+				t.Errorf("Token() error = %v", err)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	if got := len(srv.grantLog()); got != 1 {
+		t.Errorf("token requests = %d, want 1", got)
+	}
+}
+
+func TestProvider_DeviceFlowCanceled(t *testing.T) {
+	t.Parallel()
+
+	srv := newTokenServer(t)
+	srv.pending = 1000
+
+	p, _ := testProvider(t, &Config{Flow: Device, Issuer: srv.URL, ClientID: "iris"})
+	p.wait = sleep
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.Token(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Token() error = %v, want the caller's deadline", err)
+	}
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cachePath returns the cache file of the configuration, keyed by the
+// configured values only so that it is the same before and after discovery.
+// Configurations that obtain tokens from the same place for the same client
+// share it.
+func (p *Provider) cachePath() string {
+	key := strings.Join([]string{
+		p.cfg.Flow,
+		p.cfg.Issuer,
+		p.cfg.TokenURL,
+		p.cfg.ClientID,
+		p.cfg.Audience,
+		strings.Join(p.cfg.Scopes, " "),
+	}, "\n")
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(p.cacheDir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// loadCache returns the cached token, or nil when there is none.
+func (p *Provider) loadCache() *Token {
+	if p.cacheDir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(p.cachePath())
+	if err != nil {
+		return nil
+	}
+
+	var t Token
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil
+	}
+
+	return &t
+}
+
+// saveCache writes the token to the cache. The cache is best effort, so a
+// failure only means the flow runs again next time.
+func (p *Provider) saveCache(t *Token) {
+	if p.cacheDir == "" {
+		return
+	}
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(p.cacheDir, 0o700); err != nil {
+		return
+	}
+
+	_ = os.WriteFile(p.cachePath(), data, 0o600)
+}
+
+// removeCache deletes the cached token.
+func (p *Provider) removeCache() error {
+	if p.cacheDir == "" {
+		return nil
+	}
+
+	if err := os.Remove(p.cachePath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove token cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,329 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// deviceCodeGrant is the grant type of the device authorization flow (RFC 8628).
+const deviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+
+// Device flow defaults when the server does not give them.
+const (
+	defaultPollInterval = 5 * time.Second
+	defaultDeviceExpiry = 10 * time.Minute
+)
+
+// tokenResponse is a successful token endpoint response.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// oauthError is an error returned by the authorization server.
+type oauthError struct {
+	Code        string
+	Description string
+}
+
+func (e *oauthError) Error() string {
+	if e.Description != "" {
+		return e.Code + ": " + e.Description
+	}
+
+	return e.Code
+}
+
+func (p *Provider) clientCredentials(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	p.addScopes(form)
+
+	t, err := p.requestToken(ctx, form)
+	if err != nil {
+		return nil, fmt.Errorf("client credentials: %w", err)
+	}
+
+	return t, nil
+}
+
+func (p *Provider) refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+
+	t, err := p.requestToken(ctx, form)
+	if err != nil {
+		return nil, fmt.Errorf("refresh token: %w", err)
+	}
+
+	// The server may keep the refresh token and not return it again
+	if t.RefreshToken == "" {
+		t.RefreshToken = refreshToken
+	}
+
+	return t, nil
+}
+
+// deviceAuthorization is the response of the device authorization endpoint.
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	// VerificationURL is the non-standard name used by some providers.
+	VerificationURL string `json:"verification_url"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Interval        int64  `json:"interval"`
+}
+
+// deviceFlow asks the user to approve the device in a browser and polls the
+// token endpoint until they do.
+func (p *Provider) deviceFlow(ctx context.Context) (*Token, error) {
+	endpoints, err := p.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoints.DeviceAuthURL == "" {
+		return nil, fmt.Errorf("device flow: no device authorization endpoint (set device_auth_url)")
+	}
+
+	form := url.Values{}
+	p.addScopes(form)
+
+	var da deviceAuthorization
+	if err := p.postForm(ctx, endpoints.DeviceAuthURL, form, &da); err != nil {
+		return nil, fmt.Errorf("device authorization: %w", err)
+	}
+
+	uri := da.VerificationURI
+	if uri == "" {
+		uri = da.VerificationURL
+	}
+
+	fmt.Fprintf(p.prompt, "To sign in, open %s and enter the code %s\n", uri, da.UserCode)
+
+	if da.VerificationURIComplete != "" {
+		fmt.Fprintf(p.prompt, "or open %s\n", da.VerificationURIComplete)
+	}
+
+	interval := defaultPollInterval
+	if da.Interval > 0 {
+		interval = time.Duration(da.Interval) * time.Second
+	}
+
+	expiry := defaultDeviceExpiry
+	if da.ExpiresIn > 0 {
+		expiry = time.Duration(da.ExpiresIn) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, expiry)
+	defer cancel()
+
+	poll := url.Values{
+		"grant_type":  {deviceCodeGrant},
+		"device_code": {da.DeviceCode},
+	}
+
+	for {
+		if err := p.wait(ctx, interval); err != nil {
+			return nil, fmt.Errorf("device flow: waiting for sign-in: %w", err)
+		}
+
+		t, err := p.requestToken(ctx, poll)
+
+		var oe *oauthError
+		if !errors.As(err, &oe) {
+			if err != nil {
+				return nil, fmt.Errorf("device flow: %w", err)
+			}
+
+			return t, nil
+		}
+
+		switch oe.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, fmt.Errorf("device flow: %w", err)
+		}
+	}
+}
+
+func (p *Provider) addScopes(form url.Values) {
+	if len(p.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	}
+
+	if p.cfg.Audience != "" {
+		form.Set("audience", p.cfg.Audience)
+	}
+}
+
+// requestToken posts a grant to the token endpoint.
+func (p *Provider) requestToken(ctx context.Context, form url.Values) (*Token, error) {
+	endpoints, err := p.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tr tokenResponse
+	if err := p.postForm(ctx, endpoints.TokenURL, form, &tr); err != nil {
+		return nil, err
+	}
+
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+
+	// Tokens without a lifetime are renewed after a default one, and sooner
+	// if the server rejects them
+	lifetime := defaultLifetime
+	if tr.ExpiresIn > 0 {
+		lifetime = time.Duration(tr.ExpiresIn) * time.Second
+	}
+
+	return &Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+		Expiry:       p.now().Add(lifetime),
+	}, nil
+}
+
+// postForm posts form with the client credentials and decodes the JSON response
+// into v. OAuth error responses are returned as *oauthError.
+func (p *Provider) postForm(ctx context.Context, endpoint string, form url.Values, v any) error {
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+
+	var oe struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if json.Unmarshal(body, &oe) == nil && oe.Error != "" {
+		return &oauthError{Code: oe.Error, Description: oe.ErrorDescription}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	return nil
+}
+
+// endpoints is the token and device authorization endpoints.
+type endpoints struct {
+	TokenURL      string `json:"token_endpoint"`
+	DeviceAuthURL string `json:"device_authorization_endpoint"`
+}
+
+// endpoints returns the configured endpoints, discovering the missing ones
+// from the OIDC issuer metadata.
+func (p *Provider) endpoints(ctx context.Context) (*endpoints, error) {
+	e := &endpoints{TokenURL: p.cfg.TokenURL, DeviceAuthURL: p.cfg.DeviceAuthURL}
+
+	needDevice := p.cfg.Flow == Device && e.DeviceAuthURL == ""
+	if p.cfg.Issuer == "" || (e.TokenURL != "" && !needDevice) {
+		return e, nil
+	}
+
+	// Discovery runs once. The configuration is left as is, since it is the
+	// key of the token cache
+	p.mu.Lock()
+	discovered := p.discovered
+	p.mu.Unlock()
+
+	if discovered == nil {
+		var err error
+		if discovered, err = p.discover(ctx); err != nil {
+			return nil, err
+		}
+
+		p.mu.Lock()
+		p.discovered = discovered
+		p.mu.Unlock()
+	}
+
+	if e.TokenURL == "" {
+		e.TokenURL = discovered.TokenURL
+	}
+
+	if e.DeviceAuthURL == "" {
+		e.DeviceAuthURL = discovered.DeviceAuthURL
+	}
+
+	if e.TokenURL == "" {
+		return nil, fmt.Errorf("issuer %s has no token endpoint", p.cfg.Issuer)
+	}
+
+	return e, nil
+}
+
+// discover reads the OpenID Connect discovery document of the issuer.
+func (p *Provider) discover(ctx context.Context) (*endpoints, error) {
+	target := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery: %s returned %s", target, resp.Status)
+	}
+
+	var e endpoints
+	if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+		return nil, fmt.Errorf("discovery: decode %s: %w", target, err)
+	}
+
+	return &e, nil
+}
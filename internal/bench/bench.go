@@ -0,0 +1,160 @@
+// Package bench fires a GraphQL operation concurrently and summarizes the latencies.
+package bench
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+// DefaultRequests is the number of requests sent when neither a count nor a duration is given.
+const DefaultRequests = 200
+
+// Options configures a run. The run stops after Requests requests or after
+// Duration, whichever comes first; zero values disable the limit.
+type Options struct {
+	Concurrency int
+	Requests    int
+	Duration    time.Duration
+}
+
+// Result summarizes a run.
+type Result struct {
+	Concurrency int
+	Duration    time.Duration
+	// Latencies of the requests that received a response, sorted in ascending order.
+	Latencies []time.Duration
+	// GraphQLErrors counts responses with errors; TransportErrors counts
+	// requests that failed without a valid GraphQL response.
+	GraphQLErrors   int
+	TransportErrors int
+	// Errors counts the distinct error messages of both kinds.
+	Errors map[string]int
+}
+
+// Requests returns the number of completed requests.
+func (r *Result) Requests() int {
+	return len(r.Latencies) + r.TransportErrors
+}
+
+// Throughput returns the completed requests per second.
+func (r *Result) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+
+	return float64(r.Requests()) / r.Duration.Seconds()
+}
+
+// Percentile returns the latency below which p percent of the responses fall.
+func (r *Result) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+
+	i := int(float64(len(r.Latencies))*p/100+0.5) - 1
+	i = max(0, min(i, len(r.Latencies)-1))
+
+	return r.Latencies[i]
+}
+
+type sample struct {
+	latency time.Duration
+	err     string
+	gqlErr  bool
+}
+
+// Run sends req with c from opts.Concurrency goroutines.
+func Run(ctx context.Context, c *client.Client, req *client.Request, opts Options) *Result {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+
+	if opts.Requests == 0 && opts.Duration == 0 {
+		opts.Requests = DefaultRequests
+	}
+
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	var (
+		sent    atomic.Int64
+		wg      sync.WaitGroup
+		samples = make(chan sample, opts.Concurrency)
+	)
+
+	start := time.Now()
+
+	for range opts.Concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for ctx.Err() == nil {
+				if opts.Requests > 0 && sent.Add(1) > int64(opts.Requests) {
+					return
+				}
+
+				if s, ok := send(ctx, c, req); ok {
+					samples <- s
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(samples)
+	}()
+
+	res := &Result{Concurrency: opts.Concurrency, Errors: make(map[string]int)}
+
+	for s := range samples {
+		switch {
+		case s.err == "":
+			res.Latencies = append(res.Latencies, s.latency)
+		case s.gqlErr:
+			res.Latencies = append(res.Latencies, s.latency)
+			res.GraphQLErrors++
+			res.Errors[s.err]++
+		default:
+			res.TransportErrors++
+			res.Errors[s.err]++
+		}
+	}
+
+	res.Duration = time.Since(start)
+
+	sort.Slice(res.Latencies, func(i, j int) bool { return res.Latencies[i] < res.Latencies[j] })
+
+	return res
+}
+
+// send executes one request. It reports false for requests cut off by the end of the run.
+func send(ctx context.Context, c *client.Client, req *client.Request) (sample, bool) {
+	start := time.Now()
+
+	resp, err := c.Execute(ctx, req)
+	latency := time.Since(start)
+
+	switch {
+	case err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()):
+		return sample{}, false
+	case err != nil:
+		return sample{latency: latency, err: err.Error()}, true
+	case len(resp.Errors) > 0:
+		return sample{latency: latency, err: resp.Errors[0].Message, gqlErr: true}, true
+	default:
+		return sample{latency: latency}, true
+	}
+}
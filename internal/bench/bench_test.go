@@ -0,0 +1,96 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	var n atomic.Int64
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		switch n.Add(1) % 10 {
+		case 0:
+			_, _ = w.Write([]byte(`{"errors":[{"message":"rate limited"}]}`))
+		case 5:
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("bad gateway"))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"ok":true}}`))
+		}
+	}))
+	defer srv.Close()
+
+	res := Run(context.Background(), client.New(srv.URL), &client.Request{Query: "{ ok }"}, Options{Concurrency: 4, Requests: 100})
+
+	if res.Requests() != 100 || res.GraphQLErrors != 10 || res.TransportErrors != 10 {
+		t.Errorf("Requests = %d, GraphQL errors = %d, transport errors = %d; want 100, 10, 10",
+			res.Requests(), res.GraphQLErrors, res.TransportErrors)
+	}
+
+	if len(res.Latencies) != 90 || res.Errors["rate limited"] != 10 {
+		t.Errorf("len(Latencies) = %d, Errors = %v", len(res.Latencies), res.Errors)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, res); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	for _, s := range []string{"Requests:          100 (4 concurrent)", "GraphQL errors:    10", "p99", "Histogram:", "[10] rate limited"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("report should contain %q\n%s", s, buf.String())
+		}
+	}
+}
+
+func TestRun_Duration(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	res := Run(context.Background(), client.New(srv.URL), &client.Request{Query: "{ ok }"}, Options{Concurrency: 2, Duration: 50 * time.Millisecond})
+
+	if res.Requests() == 0 || res.TransportErrors != 0 {
+		t.Errorf("Requests = %d, transport errors = %d", res.Requests(), res.TransportErrors)
+	}
+}
+
+func TestPercentileAndHistogram(t *testing.T) {
+	t.Parallel()
+
+	res := &Result{}
+	for i := 1; i <= 100; i++ {
+		res.Latencies = append(res.Latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	for p, want := range map[float64]time.Duration{50: 50 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond} {
+		if got := res.Percentile(p); got != want {
+			t.Errorf("Percentile(%g) = %s, want %s", p, got, want)
+		}
+	}
+
+	buckets := res.Histogram(4)
+
+	total := 0
+	for _, b := range buckets {
+		total += b.Count
+	}
+
+	if len(buckets) != 4 || total != 100 || buckets[3].Max != 100*time.Millisecond {
+		t.Errorf("Histogram(4) = %+v", buckets)
+	}
+}
@@ -0,0 +1,144 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	histogramBuckets = 10
+	histogramWidth   = 40
+	maxErrorMessages = 5
+)
+
+// Bucket is a histogram bucket of latencies up to Max.
+type Bucket struct {
+	Max   time.Duration
+	Count int
+}
+
+// Histogram splits the latencies into n buckets of equal width.
+func (r *Result) Histogram(n int) []Bucket {
+	if len(r.Latencies) == 0 || n < 1 {
+		return nil
+	}
+
+	lo, hi := r.Latencies[0], r.Latencies[len(r.Latencies)-1]
+	width := (hi - lo) / time.Duration(n)
+
+	if width <= 0 {
+		return []Bucket{{Max: hi, Count: len(r.Latencies)}}
+	}
+
+	buckets := make([]Bucket, n)
+	for i := range buckets {
+		buckets[i].Max = lo + width*time.Duration(i+1)
+	}
+
+	buckets[n-1].Max = hi
+
+	for _, l := range r.Latencies {
+		i := min(int((l-lo)/width), n-1)
+		buckets[i].Count++
+	}
+
+	return buckets
+}
+
+// Write prints a summary, latency percentiles, a histogram and the most frequent errors.
+func Write(w io.Writer, r *Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Summary:")
+	fmt.Fprintf(tw, "  Requests:\t%d (%d concurrent)\n", r.Requests(), r.Concurrency)
+	fmt.Fprintf(tw, "  Duration:\t%s\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(tw, "  Throughput:\t%.1f req/s\n", r.Throughput())
+	fmt.Fprintf(tw, "  Succeeded:\t%d\n", len(r.Latencies)-r.GraphQLErrors)
+	fmt.Fprintf(tw, "  GraphQL errors:\t%d\n", r.GraphQLErrors)
+	fmt.Fprintf(tw, "  Transport errors:\t%d\n", r.TransportErrors)
+
+	if len(r.Latencies) > 0 {
+		fmt.Fprintln(tw, "\nLatency:")
+		fmt.Fprintf(tw, "  min\t%s\n", round(r.Latencies[0]))
+
+		for _, p := range []float64{50, 90, 95, 99} {
+			fmt.Fprintf(tw, "  p%g\t%s\n", p, round(r.Percentile(p)))
+		}
+
+		fmt.Fprintf(tw, "  max\t%s\n", round(r.Latencies[len(r.Latencies)-1]))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	writeHistogram(w, r.Histogram(histogramBuckets))
+	writeErrors(w, r.Errors)
+
+	return nil
+}
+
+func writeHistogram(w io.Writer, buckets []Bucket) {
+	if len(buckets) == 0 {
+		return
+	}
+
+	peak := 0
+	for _, b := range buckets {
+		peak = max(peak, b.Count)
+	}
+
+	fmt.Fprintln(w, "\nHistogram:")
+
+	for _, b := range buckets {
+		bar := b.Count * histogramWidth / peak
+		fmt.Fprintf(w, "  %10s [%6d] %s\n", round(b.Max), b.Count, strings.Repeat("■", bar))
+	}
+}
+
+func writeErrors(w io.Writer, errs map[string]int) {
+	if len(errs) == 0 {
+		return
+	}
+
+	msgs := make([]string, 0, len(errs))
+	for msg := range errs {
+		msgs = append(msgs, msg)
+	}
+
+	sort.Slice(msgs, func(i, j int) bool {
+		if errs[msgs[i]] != errs[msgs[j]] {
+			return errs[msgs[i]] > errs[msgs[j]]
+		}
+
+		return msgs[i] < msgs[j]
+	})
+
+	fmt.Fprintln(w, "\nErrors:")
+
+	for i, msg := range msgs {
+		if i == maxErrorMessages {
+			fmt.Fprintf(w, "  ... and %d more\n", len(msgs)-i)
+
+			break
+		}
+
+		fmt.Fprintf(w, "  [%d] %s\n", errs[msg], msg)
+	}
+}
+
+// round trims a latency to a readable precision.
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
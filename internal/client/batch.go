@@ -0,0 +1,84 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ExecuteBatch sends several requests as a JSON array in a single HTTP request
+// and returns the responses in the same order. All responses share the timing
+// of the HTTP exchange. Uploads cannot be batched, and persisted query and GET
+// options do not apply.
+func (c *Client) ExecuteBatch(ctx context.Context, reqs []*Request) ([]*Response, error) {
+	batch := make([]*Request, len(reqs))
+	query := true
+
+	for i, req := range reqs {
+		query = query && isQuery(req)
+
+		if len(req.Files) > 0 {
+			return nil, fmt.Errorf("operation %d: uploads cannot be batched", i+1)
+		}
+
+		batch[i] = req
+		if req.DocumentID != "" {
+			doc := *req
+			doc.Query = ""
+			batch[i] = &doc
+		}
+	}
+
+	ctx = withQuery(ctx, query)
+
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	respBody, timing, err := c.do(ctx, func(ctx context.Context) (*http.Request, int, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, 0, fmt.Errorf("request: %w", err)
+		}
+
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		return httpReq, len(body), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resps []*Response
+	if err := json.Unmarshal(respBody, &resps); err != nil {
+		return nil, batchError(respBody, err)
+	}
+
+	if len(resps) != len(reqs) {
+		return nil, fmt.Errorf("sent %d operations, got %d responses", len(reqs), len(resps))
+	}
+
+	for i, resp := range resps {
+		if resp == nil {
+			return nil, fmt.Errorf("operation %d: null response", i+1)
+		}
+
+		resp.Timing = timing
+	}
+
+	return resps, nil
+}
+
+// batchError explains a response that is not an array, which servers without
+// batching support usually return as a single GraphQL error.
+func batchError(body []byte, err error) error {
+	var single Response
+	if json.Unmarshal(body, &single) == nil && len(single.Errors) > 0 {
+		return fmt.Errorf("server rejected the batch: %s", single.Errors[0].Message)
+	}
+
+	return fmt.Errorf("unmarshal: %w", err)
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExecuteBatch(t *testing.T) {
+	t.Parallel()
+
+	var httpRequests int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpRequests++
+
+		var batch []Request
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			_, _ = io.WriteString(w, `{"errors":[{"message":"batching is not enabled"}]}`)
+
+			return
+		}
+
+		// Answer each operation with its index, name and document id
+		resps := make([]string, len(batch))
+		for i, req := range batch {
+			resps[i] = fmt.Sprintf(`{"data":{"index":%d,"name":%q,"doc":%q,"query":%q}}`, i, req.OperationName, req.DocumentID, req.Query)
+		}
+
+		_, _ = io.WriteString(w, "["+strings.Join(resps, ",")+"]")
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+
+	resps, err := c.ExecuteBatch(context.Background(), []*Request{
+		{Query: "query A { a } query B { b }", OperationName: "A"},
+		{Query: "query A { a } query B { b }", OperationName: "B"},
+		{Query: "{ c }", DocumentID: "doc-c"},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteBatch() error = %v", err)
+	}
+
+	if httpRequests != 1 {
+		t.Errorf("sent %d HTTP requests, want 1", httpRequests)
+	}
+
+	want := []string{
+		`{"index":0,"name":"A","doc":"","query":"query A { a } query B { b }"}`,
+		`{"index":1,"name":"B","doc":"","query":"query A { a } query B { b }"}`,
+		`{"index":2,"name":"","doc":"doc-c","query":""}`,
+	}
+
+	if len(resps) != len(want) {
+		t.Fatalf("ExecuteBatch() returned %d responses", len(resps))
+	}
+
+	for i, resp := range resps {
+		if string(resp.Data) != want[i] {
+			t.Errorf("response %d = %s, want %s", i, resp.Data, want[i])
+		}
+
+		if resp.Timing == nil {
+			t.Errorf("response %d has no timing", i)
+		}
+	}
+}
+
+func TestExecuteBatch_Errors(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/short" {
+			_, _ = io.WriteString(w, `[{"data":{}}]`)
+
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"errors":[{"message":"batching is not enabled"}]}`)
+	}))
+	defer srv.Close()
+
+	reqs := []*Request{{Query: "{ a }"}, {Query: "{ b }"}}
+
+	tests := []struct {
+		name     string
+		endpoint string
+		reqs     []*Request
+		want     string
+	}{
+		{"not supported", srv.URL, reqs, "batching is not enabled"},
+		{"missing responses", srv.URL + "/short", reqs, "sent 2 operations, got 1 responses"},
+		{"uploads", srv.URL, []*Request{{Query: "{ a }", Files: map[string]string{"f": "x"}}}, "cannot be batched"},
+	}
+
+	for _, tt := range tests {
+		_, err := New(tt.endpoint).ExecuteBatch(context.Background(), tt.reqs)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: ExecuteBatch() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
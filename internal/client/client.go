@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // Client is a GraphQL HTTP client.
@@ -15,24 +17,51 @@ type Client struct {
 	endpoint   string
 	httpClient *http.Client
 	headers    map[string]string
+	persisted  bool
+	get        bool
+	auth       Authenticator
+	middleware []Middleware
+	// sender is httpClient with the middleware wrapped around its transport.
+	sender *http.Client
 }
 
 // Request is a GraphQL request.
 type Request struct {
-	Query         string         `json:"query"`
+	Query         string         `json:"query,omitempty"`
 	Variables     map[string]any `json:"variables,omitempty"`
 	OperationName string         `json:"operationName,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+	// DocumentID identifies a trusted document known to the server. When set,
+	// it is sent instead of the query text.
+	DocumentID string `json:"documentId,omitempty"`
+
+	// Files maps variable paths, such as "avatar" or "input.photos.0", to local
+	// files sent as uploads with the GraphQL multipart request spec.
+	Files map[string]string `json:"-"`
 }
 
 // Response is a GraphQL response.
 type Response struct {
-	Data   json.RawMessage `json:"data,omitempty"`
-	Errors []Error         `json:"errors,omitempty"`
+	Data       json.RawMessage            `json:"data,omitempty"`
+	Errors     []Error                    `json:"errors,omitempty"`
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+
+	// Timing is the timing breakdown of the HTTP exchange.
+	Timing *Timing `json:"-"`
 }
 
 // Error is a GraphQL error.
 type Error struct {
-	Message string `json:"message"`
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Code returns the error code from extensions.code, or "" if there is none.
+func (e Error) Code() string {
+	code, _ := e.Extensions["code"].(string)
+
+	return code
 }
 
 // New creates a new client.
@@ -46,9 +75,56 @@ func New(endpoint string, opts ...Option) *Client {
 		opt(c)
 	}
 
+	c.sender = c.chain()
+
 	return c
 }
 
+// Clone returns a copy of the client with the given options applied.
+// The receiver is left unchanged.
+func (c *Client) Clone(opts ...Option) *Client {
+	clone := &Client{
+		endpoint:   c.endpoint,
+		httpClient: c.httpClient,
+		headers:    make(map[string]string, len(c.headers)),
+		persisted:  c.persisted,
+		get:        c.get,
+		auth:       c.auth,
+		middleware: c.middleware,
+	}
+	for k, v := range c.headers {
+		clone.headers[k] = v
+	}
+
+	for _, opt := range opts {
+		opt(clone)
+	}
+
+	clone.sender = clone.chain()
+
+	return clone
+}
+
+// Endpoint returns the endpoint URL.
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// Headers returns a copy of the configured headers.
+func (c *Client) Headers() map[string]string {
+	headers := make(map[string]string, len(c.headers))
+	for k, v := range c.headers {
+		headers[k] = v
+	}
+
+	return headers
+}
+
+// Middleware returns the middleware chain, outermost first.
+func (c *Client) Middleware() []Middleware {
+	return c.middleware[:len(c.middleware):len(c.middleware)]
+}
+
 // Option configures the client.
 type Option func(*Client)
 
@@ -59,40 +135,261 @@ func WithHeader(key, value string) Option {
 	}
 }
 
+// WithoutHeader removes a header.
+func WithoutHeader(key string) Option {
+	return func(c *Client) {
+		for k := range c.headers {
+			if strings.EqualFold(k, key) {
+				delete(c.headers, k)
+			}
+		}
+	}
+}
+
+// WithEndpoint sets the endpoint URL.
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		c.endpoint = endpoint
+	}
+}
+
+// WithTimeout sets the timeout for each request.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		hc := *c.httpClient
+		hc.Timeout = d
+		c.httpClient = &hc
+	}
+}
+
+// WithH2C sends requests to http:// URLs with unencrypted HTTP/2 using prior
+// knowledge (h2c), for local servers that only speak HTTP/2. Requests to
+// https:// URLs then require HTTP/2 as well. It has no effect on a transport
+// set with WithTransport that is not an *http.Transport.
+func WithH2C() Option {
+	return func(c *Client) {
+		rt := c.httpClient.Transport
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+
+		base, ok := rt.(*http.Transport)
+		if !ok {
+			return
+		}
+
+		var protocols http.Protocols
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+
+		t := base.Clone()
+		t.Protocols = &protocols
+
+		hc := *c.httpClient
+		hc.Transport = t
+		c.httpClient = &hc
+	}
+}
+
+// Authenticator adds credentials to each HTTP request, such as an
+// Authorization header with a token it keeps fresh.
+type Authenticator interface {
+	Authorize(ctx context.Context, req *http.Request) error
+}
+
+// Invalidator is implemented by authenticators whose credentials the server
+// may reject. When a response is 401 Unauthorized, the client calls
+// Invalidate and sends the request again once.
+type Invalidator interface {
+	Invalidate()
+}
+
+// WithAuth sets the authenticator called before each request is sent.
+// Its credentials take precedence over headers set with WithHeader.
+func WithAuth(a Authenticator) Option {
+	return func(c *Client) {
+		c.auth = a
+	}
+}
+
+// WithTransport sets the transport used to send requests.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		hc := *c.httpClient
+		hc.Transport = rt
+		c.httpClient = &hc
+	}
+}
+
+// WithCookieJar stores the cookies set by responses in jar and sends them
+// with later requests, as for a session cookie set by a login mutation.
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(c *Client) {
+		hc := *c.httpClient
+		hc.Jar = jar
+		c.httpClient = &hc
+	}
+}
+
+// CookieJar returns the cookie jar, or nil when cookies are not kept.
+func (c *Client) CookieJar() http.CookieJar {
+	return c.httpClient.Jar
+}
+
 // Execute sends a request.
 func (c *Client) Execute(ctx context.Context, req *Request) (*Response, error) {
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("marshal: %w", err)
+	ctx = withQuery(ctx, isQuery(req))
+
+	if c.persisted && req.DocumentID == "" && len(req.Files) == 0 {
+		return c.executePersisted(ctx, req)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	out, get := c.firstRequest(req)
+
+	return c.send(ctx, out, get)
+}
+
+// firstRequest returns the request sent first for req, and whether it is
+// sent as a GET: without the query text for a trusted document or an
+// automatic persisted query.
+func (c *Client) firstRequest(req *Request) (*Request, bool) {
+	switch {
+	case req.DocumentID != "":
+		doc := *req
+		doc.Query = ""
+
+		return &doc, c.useGET(req)
+	case c.persisted && len(req.Files) == 0:
+		return persistedRequest(req, false), c.useGET(req)
+	default:
+		return req, c.useGET(req)
+	}
+}
+
+// send sends a single HTTP request, as a GET with URL parameters when get is set.
+func (c *Client) send(ctx context.Context, req *Request, get bool) (*Response, error) {
+	respBody, timing, err := c.do(ctx, func(ctx context.Context) (*http.Request, int, error) {
+		return c.newHTTPRequest(ctx, req, get)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("request: %w", err)
+		return nil, err
+	}
+
+	var resp Response
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
+	resp.Timing = timing
+
+	return &resp, nil
+}
+
+// do sends the HTTP request made by build, which also returns the size of
+// the request body, and returns the response body and the timing.
+func (c *Client) do(ctx context.Context, build func(ctx context.Context) (*http.Request, int, error)) ([]byte, *Timing, error) {
+	respBody, timing, status, err := c.roundTrip(ctx, build)
+
+	// A rejected credential is dropped and the request sent once more with a fresh one
+	if inv, ok := c.auth.(Invalidator); ok && err == nil && status == http.StatusUnauthorized {
+		inv.Invalidate()
+
+		respBody, timing, _, err = c.roundTrip(ctx, build)
+	}
+
+	return respBody, timing, err
+}
+
+// roundTrip sends the HTTP request made by build once and returns the
+// response body, the timing and the status code.
+func (c *Client) roundTrip(ctx context.Context, build func(ctx context.Context) (*http.Request, int, error)) ([]byte, *Timing, int, error) {
+	tr := newTracer()
+	ctx = tr.trace(ctx)
+
+	httpReq, size, err := build(ctx)
+	if err != nil {
+		return nil, nil, 0, err
+	}
 
 	for k, v := range c.headers {
 		httpReq.Header.Set(k, v)
 	}
 
-	httpResp, err := c.httpClient.Do(httpReq)
+	if c.auth != nil {
+		if err := c.auth.Authorize(ctx, httpReq); err != nil {
+			return nil, nil, 0, fmt.Errorf("authorize: %w", err)
+		}
+	}
+
+	httpResp, err := c.sender.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("send: %w", err)
+		return nil, nil, 0, fmt.Errorf("send: %w", err)
 	}
 
 	defer func() { _ = httpResp.Body.Close() }()
 
 	respBody, err := io.ReadAll(httpResp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read: %w", err)
+		return nil, nil, 0, fmt.Errorf("read: %w", err)
 	}
 
-	var resp Response
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("unmarshal: %w", err)
+	return respBody, tr.finish(httpResp.Proto, size, len(respBody)), httpResp.StatusCode, nil
+}
+
+// newHTTPRequest builds the HTTP request and returns the size of its body.
+func (c *Client) newHTTPRequest(ctx context.Context, req *Request, get bool) (*http.Request, int, error) {
+	if get {
+		target, err := getURL(c.endpoint, req)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return nil, 0, fmt.Errorf("request: %w", err)
+		}
+
+		setPreflight(httpReq.Header)
+
+		return httpReq, 0, nil
 	}
 
-	return &resp, nil
+	body, contentType, err := encode(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", contentType)
+
+	if len(req.Files) > 0 {
+		setPreflight(httpReq.Header)
+	}
+
+	return httpReq, len(body), nil
+}
+
+// setPreflight marks a request that CORS treats as simple, such as a GET or a
+// multipart POST, for servers with CSRF prevention such as Apollo Server.
+func setPreflight(h http.Header) {
+	h.Set("Apollo-Require-Preflight", "true")
+}
+
+// encode returns the request body and its content type: JSON, or multipart
+// form data when the request has files.
+func encode(req *Request) (body []byte, contentType string, err error) {
+	if len(req.Files) > 0 {
+		return encodeMultipart(req)
+	}
+
+	body, err = json.Marshal(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("marshal: %w", err)
+	}
+
+	return body, "application/json", nil
 }
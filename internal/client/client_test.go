@@ -0,0 +1,405 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	orig := New("http://a.example/graphql", WithHeader("Authorization", "Bearer x"), WithHeader("X-Trace", "1"))
+	clone := orig.Clone(WithEndpoint("http://b.example/graphql"), WithoutHeader("authorization"), WithHeader("X-New", "y"))
+
+	if got := orig.Endpoint(); got != "http://a.example/graphql" {
+		t.Errorf("original Endpoint() = %q, want unchanged", got)
+	}
+
+	if got := orig.Headers(); len(got) != 2 || got["Authorization"] != "Bearer x" {
+		t.Errorf("original Headers() = %v, want unchanged", got)
+	}
+
+	if got := clone.Endpoint(); got != "http://b.example/graphql" {
+		t.Errorf("clone Endpoint() = %q", got)
+	}
+
+	headers := clone.Headers()
+	if _, ok := headers["Authorization"]; ok {
+		t.Errorf("clone Headers() = %v, Authorization should be removed case-insensitively", headers)
+	}
+
+	if headers["X-Trace"] != "1" || headers["X-New"] != "y" {
+		t.Errorf("clone Headers() = %v", headers)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWithTransport(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"data":{"ok":true}}`)),
+			Request:    r,
+		}, nil
+	})
+
+	// The timeout must not drop the transport, whatever the order
+	c := New("http://example.invalid/graphql", WithTransport(rt), WithTimeout(time.Second))
+
+	if _, err := c.Execute(context.Background(), &Request{Query: "{ ok }"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("transport called %d times, want 1", calls)
+	}
+
+	if http.DefaultClient.Timeout != 0 {
+		t.Error("WithTimeout modified http.DefaultClient")
+	}
+}
+
+func TestWithCookieJar(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session"); err == nil {
+			_, _ = io.WriteString(w, `{"data":{"me":"`+c.Value+`"}}`)
+
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/"})
+		_, _ = io.WriteString(w, `{"data":{"login":true}}`)
+	}))
+	defer srv.Close()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(srv.URL, WithCookieJar(jar), WithTimeout(time.Second))
+
+	if _, err := c.Execute(context.Background(), &Request{Query: "mutation { login }"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	resp, err := c.Clone().Execute(context.Background(), &Request{Query: "{ me }"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if got := string(resp.Data); got != `{"me":"s1"}` {
+		t.Errorf("Data = %s, want the session cookie sent back", got)
+	}
+
+	if c.CookieJar() != jar {
+		t.Error("CookieJar() did not return the jar")
+	}
+
+	if http.DefaultClient.Jar != nil {
+		t.Error("WithCookieJar modified http.DefaultClient")
+	}
+}
+
+type authFunc func(context.Context, *http.Request) error
+
+func (f authFunc) Authorize(ctx context.Context, req *http.Request) error {
+	return f(ctx, req)
+}
+
+func TestWithAuth(t *testing.T) {
+	t.Parallel()
+
+	var got string
+
+	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r.Header.Get("Authorization")
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"data":{"ok":true}}`)),
+			Request:    r,
+		}, nil
+	})
+
+	a := authFunc(func(_ context.Context, r *http.Request) error {
+		r.Header.Set("Authorization", "Bearer fresh")
+
+		return nil
+	})
+
+	// The authenticator overrides a static header and survives Clone
+	c := New("http://example.invalid/graphql", WithTransport(rt), WithHeader("Authorization", "Bearer stale"), WithAuth(a)).Clone()
+
+	if _, err := c.Execute(context.Background(), &Request{Query: "{ ok }"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if got != "Bearer fresh" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer fresh")
+	}
+
+	failing := c.Clone(WithAuth(authFunc(func(context.Context, *http.Request) error {
+		return errors.New("no token")
+	})))
+
+	if _, err := failing.Execute(context.Background(), &Request{Query: "{ ok }"}); err == nil || !strings.Contains(err.Error(), "authorize: no token") {
+		t.Errorf("Execute() error = %v, want the authorize error", err)
+	}
+}
+
+// rotatingAuth hands out a new token each time the previous one is invalidated.
+type rotatingAuth struct {
+	token       int
+	invalidated int
+}
+
+func (a *rotatingAuth) Authorize(_ context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %d", a.token))
+
+	return nil
+}
+
+func (a *rotatingAuth) Invalidate() {
+	a.invalidated++
+	a.token++
+}
+
+func TestWithAuth_Unauthorized(t *testing.T) {
+	t.Parallel()
+
+	var seen []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+
+		// Only the second token is accepted
+		if r.Header.Get("Authorization") != "Bearer 1" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+
+		_, _ = io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+
+	a := &rotatingAuth{}
+	c := New(srv.URL, WithAuth(a))
+
+	if _, err := c.Execute(context.Background(), &Request{Query: "{ ok }"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if strings.Join(seen, ",") != "Bearer 0,Bearer 1" || a.invalidated != 1 {
+		t.Errorf("sent %v with %d invalidations, want a retry with a fresh token", seen, a.invalidated)
+	}
+
+	// A second rejection is returned instead of looping
+	a.token = 5
+	seen = nil
+
+	if _, err := c.Execute(context.Background(), &Request{Query: "{ ok }"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if len(seen) != 2 {
+		t.Errorf("sent %d requests, want 2", len(seen))
+	}
+}
+
+func TestExecute_Timing(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"data":{"ok":true}}`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	resp, err := New(srv.URL).Execute(context.Background(), &Request{Query: "{ ok }"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	timing := resp.Timing
+	if timing == nil {
+		t.Fatal("Execute() Timing = nil")
+	}
+
+	if timing.RequestSize != int64(len(`{"query":"{ ok }"}`)) {
+		t.Errorf("RequestSize = %d", timing.RequestSize)
+	}
+
+	if timing.ResponseSize != int64(len(body)) {
+		t.Errorf("ResponseSize = %d, want %d", timing.ResponseSize, len(body))
+	}
+
+	if timing.TTFB <= 0 || timing.Total < timing.TTFB {
+		t.Errorf("TTFB = %v, Total = %v", timing.TTFB, timing.Total)
+	}
+
+	if s := timing.String(); !strings.Contains(s, "received 20B") {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestExecute_Files(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{"me.png": "PNG", "a.txt": "A", "b.txt": "B"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		operations, fileMap string
+		files               = make(map[string]string)
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Apollo-Require-Preflight") == "" {
+			t.Error("missing preflight header")
+		}
+
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm() error = %v", err)
+
+			return
+		}
+
+		operations, fileMap = r.FormValue("operations"), r.FormValue("map")
+
+		for name, headers := range r.MultipartForm.File {
+			f, _ := headers[0].Open()
+			data, _ := io.ReadAll(f)
+			files[name] = headers[0].Filename + ":" + headers[0].Header.Get("Content-Type") + ":" + string(data)
+		}
+
+		_, _ = io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+
+	req := &Request{
+		Query:     "mutation($avatar: Upload!, $input: PostInput!) { ok }",
+		Variables: map[string]any{"input": map[string]any{"title": "t"}},
+		Files: map[string]string{
+			"avatar":              filepath.Join(dir, "me.png"),
+			"input.attachments.0": filepath.Join(dir, "a.txt"),
+			"input.attachments.1": filepath.Join(dir, "b.txt"),
+		},
+	}
+
+	if _, err := New(srv.URL).Execute(context.Background(), req); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var ops struct {
+		Variables map[string]any `json:"variables"`
+	}
+	if err := json.Unmarshal([]byte(operations), &ops); err != nil {
+		t.Fatalf("operations = %q: %v", operations, err)
+	}
+
+	input, _ := json.Marshal(ops.Variables["input"])
+	if v, ok := ops.Variables["avatar"]; !ok || v != nil || string(input) != `{"attachments":[null,null],"title":"t"}` {
+		t.Errorf("operations variables = %v", ops.Variables)
+	}
+
+	if want := `{"0":["variables.avatar"],"1":["variables.input.attachments.0"],"2":["variables.input.attachments.1"]}`; fileMap != want {
+		t.Errorf("map = %s, want %s", fileMap, want)
+	}
+
+	if files["0"] != "me.png:image/png:PNG" || !strings.HasSuffix(files["2"], ":B") {
+		t.Errorf("files = %v", files)
+	}
+
+	if _, ok := req.Variables["avatar"]; ok {
+		t.Error("Execute() should not modify the request variables")
+	}
+}
+
+func TestExecute_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	req := &Request{Query: "mutation { ok }", Files: map[string]string{"f": filepath.Join(t.TempDir(), "none")}}
+	if _, err := New("http://example.invalid/graphql").Execute(context.Background(), req); err == nil {
+		t.Error("Execute() expected error for a missing file")
+	}
+}
+
+func TestExecute_DocumentID(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			got = append(got, "GET "+r.URL.RawQuery)
+		} else {
+			body, _ := io.ReadAll(r.Body)
+			got = append(got, "POST "+string(body))
+		}
+
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	req := &Request{Query: "{ me { id } }", DocumentID: "sha256:abc"}
+
+	for _, c := range []*Client{New(srv.URL, WithPersistedQueries()), New(srv.URL, WithGET())} {
+		if _, err := c.Execute(context.Background(), req); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+
+	want := []string{`POST {"documentId":"sha256:abc"}`, "GET documentId=sha256%3Aabc"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+
+	if req.Query == "" {
+		t.Error("Execute() should not modify the request")
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[int64]string{
+		0:       "0B",
+		1023:    "1023B",
+		1536:    "1.5KB",
+		5 << 20: "5.0MB",
+	}
+
+	for n, want := range tests {
+		if got := formatSize(n); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// acceptEncoding lists the response encodings the client decodes, densest first.
+const acceptEncoding = "zstd, br, gzip"
+
+// decompress asks for compressed responses and decodes them, so that the
+// middleware and the caller see the plain body. Requests that already set
+// Accept-Encoding get the response as the server sent it.
+func decompress(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Accept-Encoding") != "" {
+			return next.RoundTrip(req) //nolint:wrapcheck // middleware passes errors through
+		}
+
+		// RoundTrippers must not modify the caller's request
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // middleware passes errors through
+		}
+
+		encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+		if encoding == "" || encoding == "identity" || !hasBody(req, resp) {
+			return resp, nil
+		}
+
+		body, err := newDecoder(encoding, &countingReader{r: resp.Body, ctx: req.Context()})
+		if err != nil {
+			_ = resp.Body.Close()
+
+			return nil, err
+		}
+
+		recordEncoding(req.Context(), encoding)
+
+		resp.Body = &decodedBody{Reader: body, body: resp.Body}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+
+		return resp, nil
+	})
+}
+
+// hasBody reports whether resp may have a body, which HEAD requests and
+// 204 and 304 responses do not.
+func hasBody(req *http.Request, resp *http.Response) bool {
+	return req.Method != http.MethodHead &&
+		resp.StatusCode != http.StatusNoContent &&
+		resp.StatusCode != http.StatusNotModified &&
+		resp.Body != http.NoBody
+}
+
+// newDecoder returns a reader of the body decoded from encoding.
+func newDecoder(encoding string, r io.Reader) (io.Reader, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("decode gzip response: %w", err)
+		}
+
+		return zr, nil
+	case "br":
+		return brotli.NewReader(r), nil
+	case "zstd":
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("decode zstd response: %w", err)
+		}
+
+		return zr.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported response Content-Encoding %q", encoding)
+	}
+}
+
+// decodedBody reads the decoded response and closes both the decoder and the
+// response body.
+type decodedBody struct {
+	io.Reader
+
+	body io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	if c, ok := b.Reader.(io.Closer); ok {
+		_ = c.Close()
+	}
+
+	return b.body.Close() //nolint:wrapcheck // the error of the response body is returned as is
+}
+
+// countingReader adds the bytes read from the wire to the timing of ctx.
+type countingReader struct {
+	r   io.Reader
+	ctx context.Context //nolint:containedctx // the timing lives in the request context
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+
+	if t, ok := c.ctx.Value(tracerKey{}).(*tracer); ok {
+		t.timing.EncodedSize += int64(n)
+	}
+
+	return n, err //nolint:wrapcheck // io.Reader errors such as io.EOF are returned as is
+}
+
+// recordEncoding sets the response encoding on the timing of ctx.
+func recordEncoding(ctx context.Context, encoding string) {
+	if t, ok := ctx.Value(tracerKey{}).(*tracer); ok {
+		t.timing.Encoding = encoding
+	}
+}
+
+// recordSent records the encoding and size of the request body as it is sent,
+// after the middleware that may have compressed it.
+func recordSent(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if t, ok := req.Context().Value(tracerKey{}).(*tracer); ok {
+			if encoding := req.Header.Get("Content-Encoding"); encoding != "" {
+				t.timing.RequestEncoding = encoding
+				t.timing.RequestEncodedSize = req.ContentLength
+			}
+		}
+
+		return next.RoundTrip(req) //nolint:wrapcheck // middleware passes errors through
+	})
+}
+
+// CompressRequests gzips request bodies of at least minSize bytes and sends
+// them with Content-Encoding: gzip, for large mutations and uploads. The
+// server must accept compressed requests. Requests that already set
+// Content-Encoding are sent as is.
+func CompressRequests(minSize int) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+				return next.RoundTrip(req) //nolint:wrapcheck // middleware passes errors through
+			}
+
+			body, err := readBody(req)
+			if err != nil {
+				return nil, err
+			}
+
+			// RoundTrippers must not modify the caller's request
+			req = req.Clone(req.Context())
+
+			if len(body) >= minSize {
+				if body, err = gzipBytes(body); err != nil {
+					return nil, err
+				}
+
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			req.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+			req.ContentLength = int64(len(body))
+
+			return next.RoundTrip(req) //nolint:wrapcheck // middleware passes errors through
+		})
+	}
+}
+
+// readBody returns the request body, read from GetBody when it is set so
+// that req can still be sent as is.
+func readBody(req *http.Request) ([]byte, error) {
+	rc := req.Body
+	if req.GetBody != nil {
+		var err error
+		if rc, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("read body: %w", err)
+		}
+	}
+
+	defer func() { _ = rc.Close() }()
+
+	body, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+
+	return body, nil
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("compress body: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compress body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
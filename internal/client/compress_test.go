@@ -0,0 +1,247 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func encodeBody(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	var w io.WriteCloser
+
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "zstd":
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w = zw
+	default:
+		return data
+	}
+
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	t.Parallel()
+
+	body := `{"data":{"types":"` + strings.Repeat("Query ", 500) + `"}}`
+
+	tests := []struct {
+		name     string
+		encoding string
+		header   string
+		wantSent string
+	}{
+		{name: "gzip", encoding: "gzip", wantSent: acceptEncoding},
+		{name: "brotli", encoding: "br", wantSent: acceptEncoding},
+		{name: "zstd", encoding: "zstd", wantSent: acceptEncoding},
+		{name: "plain", encoding: "", wantSent: acceptEncoding},
+		{name: "accept encoding set by the caller", encoding: "", header: "identity", wantSent: "identity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			encoded := encodeBody(t, tt.encoding, []byte(body))
+
+			var sent string
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sent = r.Header.Get("Accept-Encoding")
+
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+
+				_, _ = w.Write(encoded)
+			}))
+			defer srv.Close()
+
+			var opts []Option
+			if tt.header != "" {
+				opts = append(opts, WithHeader("Accept-Encoding", tt.header))
+			}
+
+			resp, err := New(srv.URL, opts...).Execute(context.Background(), &Request{Query: "{ types }"})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if sent != tt.wantSent {
+				t.Errorf("Accept-Encoding = %q, want %q", sent, tt.wantSent)
+			}
+
+			if want := `{"types":"` + strings.Repeat("Query ", 500) + `"}`; string(resp.Data) != want {
+				t.Errorf("Data = %.40s..., want the decoded body", resp.Data)
+			}
+
+			timing := resp.Timing
+			if timing.Encoding != tt.encoding {
+				t.Errorf("Encoding = %q, want %q", timing.Encoding, tt.encoding)
+			}
+
+			if tt.encoding != "" && timing.EncodedSize != int64(len(encoded)) {
+				t.Errorf("EncodedSize = %d, want %d", timing.EncodedSize, len(encoded))
+			}
+
+			if timing.ResponseSize != int64(len(body)) {
+				t.Errorf("ResponseSize = %d, want %d", timing.ResponseSize, len(body))
+			}
+		})
+	}
+}
+
+func TestDecompress_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Encoding", "compress")
+		_, _ = io.WriteString(w, "???")
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL).Execute(context.Background(), &Request{Query: "{ ok }"})
+	if err == nil || !strings.Contains(err.Error(), `unsupported response Content-Encoding "compress"`) {
+		t.Errorf("Execute() error = %v, want an unsupported encoding error", err)
+	}
+}
+
+func TestCompressRequests(t *testing.T) {
+	t.Parallel()
+
+	type received struct {
+		encoding string
+		query    string
+	}
+
+	var got []received
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := io.Reader(r.Body)
+
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+
+				return
+			}
+
+			body = zr
+		}
+
+		data, _ := io.ReadAll(body)
+		got = append(got, received{encoding: r.Header.Get("Content-Encoding"), query: string(data)})
+
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithMiddleware(CompressRequests(1024)))
+
+	large := "mutation { import(rows: \"" + strings.Repeat("x", 2048) + "\") }"
+
+	var timings []*Timing
+
+	for _, q := range []string{"{ ok }", large} {
+		resp, err := c.Execute(context.Background(), &Request{Query: q})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+
+		timings = append(timings, resp.Timing)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("server received %d requests, want 2", len(got))
+	}
+
+	if got[0].encoding != "" {
+		t.Errorf("small body Content-Encoding = %q, want none", got[0].encoding)
+	}
+
+	if got[1].encoding != "gzip" || !strings.Contains(got[1].query, strings.Repeat("x", 2048)) {
+		t.Errorf("large body Content-Encoding = %q, body = %.40s...", got[1].encoding, got[1].query)
+	}
+
+	// The timing gives the size sent next to the size of the body
+	if small := timings[0]; small.RequestEncoding != "" || small.RequestEncodedSize != 0 {
+		t.Errorf("small body timing = %+v, want no request encoding", small)
+	}
+
+	if large := timings[1]; large.RequestEncoding != "gzip" || large.RequestEncodedSize <= 0 || large.RequestEncodedSize >= large.RequestSize {
+		t.Errorf("large body timing: %d bytes, %d bytes %q sent", large.RequestSize, large.RequestEncodedSize, large.RequestEncoding)
+	}
+}
+
+func TestWithH2C(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"proto":"`+r.Proto+`"}}`)
+	}))
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	srv.Config.Protocols = &protocols
+
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{name: "default", want: "HTTP/1.1"},
+		{name: "h2c", opts: []Option{WithH2C()}, want: "HTTP/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := New(srv.URL, tt.opts...).Execute(context.Background(), &Request{Query: "{ proto }"})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if resp.Timing.Protocol != tt.want {
+				t.Errorf("Protocol = %q, want %q", resp.Timing.Protocol, tt.want)
+			}
+
+			if want := `{"proto":"` + tt.want + `"}`; string(resp.Data) != want {
+				t.Errorf("Data = %s, want %s", resp.Data, want)
+			}
+		})
+	}
+}
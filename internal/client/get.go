@@ -0,0 +1,83 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// WithGET sends queries as HTTP GET requests with URL-encoded parameters,
+// so that caches and CDNs can serve them. Mutations, subscriptions and
+// uploads are still sent as POST.
+func WithGET() Option {
+	return func(c *Client) {
+		c.get = true
+	}
+}
+
+// useGET reports whether req is sent as a GET request.
+func (c *Client) useGET(req *Request) bool {
+	return c.get && len(req.Files) == 0 && isQuery(req)
+}
+
+// isQuery reports whether the operation to run is a query. Documents that
+// do not parse are treated as mutations, leaving the error to the server.
+func isQuery(req *Request) bool {
+	doc, err := parser.ParseQuery(&ast.Source{Input: req.Query})
+	if err != nil {
+		return false
+	}
+
+	var op *ast.OperationDefinition
+
+	if req.OperationName != "" {
+		op = doc.Operations.ForName(req.OperationName)
+	} else if len(doc.Operations) == 1 {
+		op = doc.Operations[0]
+	}
+
+	return op != nil && op.Operation == ast.Query
+}
+
+// getURL encodes req as the query string of the endpoint, following the
+// GraphQL over HTTP GET conventions.
+func getURL(endpoint string, req *Request) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	params := u.Query()
+
+	if req.Query != "" {
+		params.Set("query", req.Query)
+	}
+
+	if req.DocumentID != "" {
+		params.Set("documentId", req.DocumentID)
+	}
+
+	if req.OperationName != "" {
+		params.Set("operationName", req.OperationName)
+	}
+
+	for name, v := range map[string]map[string]any{"variables": req.Variables, "extensions": req.Extensions} {
+		if len(v) == 0 {
+			continue
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("marshal %s: %w", name, err)
+		}
+
+		params.Set(name, string(data))
+	}
+
+	u.RawQuery = params.Encode()
+
+	return u.String(), nil
+}
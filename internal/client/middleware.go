@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Middleware wraps the transport that sends each HTTP request. It sees the
+// request after the headers and the authenticator are applied, so it can
+// sign, log, rewrite or resend it.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to an http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// WithMiddleware appends middleware to the chain. The first middleware is the
+// outermost: it sees the request first and the response last.
+func WithMiddleware(mws ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware[:len(c.middleware):len(c.middleware)], mws...)
+	}
+}
+
+// chain returns the HTTP client with the middleware wrapped around its
+// transport, and the decoding of compressed responses innermost. New and
+// Clone build it once, after all options are applied.
+func (c *Client) chain() *http.Client {
+	rt := c.httpClient.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	rt = decompress(recordSent(rt))
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+
+	hc := *c.httpClient
+	hc.Transport = rt
+
+	return &hc
+}
+
+type queryKey struct{}
+
+// withQuery marks ctx with whether the request being sent only runs queries.
+func withQuery(ctx context.Context, query bool) context.Context {
+	return context.WithValue(ctx, queryKey{}, query)
+}
+
+// IsQuery reports whether the client marked the request of ctx as running
+// only queries, which are safe to send again. Mutations, subscriptions and
+// documents that do not parse are not.
+func IsQuery(ctx context.Context) bool {
+	query, _ := ctx.Value(queryKey{}).(bool)
+
+	return query
+}
+
+// Logging logs the method and URL of each request, then its status and
+// duration, to w.
+func Logging(w io.Writer) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+
+			fmt.Fprintf(w, "--> %s %s\n", req.Method, req.URL.Redacted())
+
+			resp, err := next.RoundTrip(req)
+			elapsed := time.Since(start).Round(time.Millisecond)
+
+			if err != nil {
+				fmt.Fprintf(w, "<-- %s %s failed after %s: %v\n", req.Method, req.URL.Redacted(), elapsed, err)
+
+				return nil, err //nolint:wrapcheck // middleware passes errors through
+			}
+
+			fmt.Fprintf(w, "<-- %s %s (%s, %s)\n", resp.Status, req.URL.Redacted(), elapsed, resp.Proto)
+
+			return resp, nil
+		})
+	}
+}
+
+// EnvHeaders sets a header for each environment variable with the given
+// prefix, read once when the middleware is created. The rest of the variable
+// name is the header name, with underscores as dashes: with the prefix
+// IRIS_HEADER_, IRIS_HEADER_X_API_KEY=k sets X-Api-Key: k. Headers already on
+// the request are kept.
+func EnvHeaders(prefix string) Middleware {
+	injected := make(http.Header)
+
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+
+		injected.Set(strings.ReplaceAll(name[len(prefix):], "_", "-"), value)
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		if len(injected) == 0 {
+			return next
+		}
+
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// RoundTrippers must not modify the caller's request
+			req = req.Clone(req.Context())
+
+			for k, v := range injected {
+				if req.Header.Get(k) == "" {
+					req.Header[k] = v
+				}
+			}
+
+			return next.RoundTrip(req) //nolint:wrapcheck // middleware passes errors through
+		})
+	}
+}
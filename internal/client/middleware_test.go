@@ -0,0 +1,105 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithMiddleware_Order(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"seen":"`+r.Header.Get("X-Seen")+`"}}`)
+	}))
+	defer srv.Close()
+
+	var order []string
+
+	mark := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+
+				req = req.Clone(req.Context())
+				req.Header.Set("X-Seen", req.Header.Get("X-Seen")+name)
+
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	base := New(srv.URL, WithMiddleware(mark("a")))
+	c := base.Clone(WithMiddleware(mark("b"), mark("c")))
+
+	resp, err := c.Execute(context.Background(), &Request{Query: "{ seen }"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if got := string(resp.Data); got != `{"seen":"abc"}` {
+		t.Errorf("Data = %s, want the middleware applied in order", got)
+	}
+
+	// Clone must not share the appended chain with the original
+	order = nil
+
+	if _, err := base.Execute(context.Background(), &Request{Query: "{ seen }"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if strings.Join(order, "") != "a" {
+		t.Errorf("original chain = %v, want [a]", order)
+	}
+}
+
+func TestLogging(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+
+	c := New(srv.URL, WithMiddleware(Logging(&buf)))
+	if _, err := c.Execute(context.Background(), &Request{Query: "{ ok }"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, "--> POST "+srv.URL) || !strings.Contains(got, "<-- 200 OK "+srv.URL) {
+		t.Errorf("log = %q", got)
+	}
+}
+
+func TestEnvHeaders(t *testing.T) {
+	t.Setenv("IRIS_TEST_HDR_X_API_KEY", "key")
+	t.Setenv("IRIS_TEST_HDR_AUTHORIZATION", "Bearer env")
+
+	var got http.Header
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithHeader("Authorization", "Bearer flag"), WithMiddleware(EnvHeaders("IRIS_TEST_HDR_")))
+	if _, err := c.Execute(context.Background(), &Request{Query: "{ ok }"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if got.Get("X-Api-Key") != "key" {
+		t.Errorf("X-Api-Key = %q, want %q", got.Get("X-Api-Key"), "key")
+	}
+
+	if got.Get("Authorization") != "Bearer flag" {
+		t.Errorf("Authorization = %q, want the header set on the client", got.Get("Authorization"))
+	}
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// encodeMultipart encodes a request with files following the GraphQL multipart
+// request spec, with the fields of multipartForm.
+func encodeMultipart(req *Request) (body []byte, contentType string, err error) {
+	form, err := multipartForm(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+
+	w := multipart.NewWriter(&buf)
+
+	for _, f := range form {
+		if !f.File {
+			if err := w.WriteField(f.Name, f.Value); err != nil {
+				return nil, "", fmt.Errorf("write %s: %w", f.Name, err)
+			}
+
+			continue
+		}
+
+		if err := writeFile(w, f.Name, f.Value); err != nil {
+			return nil, "", fmt.Errorf("upload %s: %w", f.Value, err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", fmt.Errorf("close multipart: %w", err)
+	}
+
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// multipartForm returns the fields of a request with files: an "operations"
+// field holding the request with null in place of each file, a "map" field
+// from file field names to variable paths, and one field per file.
+func multipartForm(req *Request) ([]FormField, error) {
+	operations, err := withNullFiles(req)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := slices.Sorted(maps.Keys(req.Files))
+
+	fileMap := make(map[string][]string, len(paths))
+	for i, path := range paths {
+		fileMap[strconv.Itoa(i)] = []string{"variables." + path}
+	}
+
+	mapJSON, err := json.Marshal(fileMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal map: %w", err)
+	}
+
+	form := []FormField{
+		{Name: "operations", Value: string(operations)},
+		{Name: "map", Value: string(mapJSON)},
+	}
+
+	for i, path := range paths {
+		form = append(form, FormField{Name: strconv.Itoa(i), Value: req.Files[path], File: true})
+	}
+
+	return form, nil
+}
+
+// withNullFiles returns the JSON request with each file path set to null in the variables.
+func withNullFiles(req *Request) ([]byte, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	var operations map[string]any
+	if err := json.Unmarshal(data, &operations); err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	vars, _ := operations["variables"].(map[string]any)
+	if vars == nil {
+		vars = make(map[string]any)
+	}
+
+	var root any = vars
+
+	for path := range req.Files {
+		if root, err = setNull(root, strings.Split(path, ".")); err != nil {
+			return nil, fmt.Errorf("file variable %s: %w", path, err)
+		}
+	}
+
+	operations["variables"] = root
+
+	data, err = json.Marshal(operations)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	return data, nil
+}
+
+// setNull sets the value at path to null, creating objects and lists on the way.
+// Numeric segments index lists.
+func setNull(v any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	key, rest := path[0], path[1:]
+
+	if i, err := strconv.Atoi(key); err == nil && i >= 0 {
+		list, ok := v.([]any)
+		if !ok && v != nil {
+			return nil, fmt.Errorf("%s is not a list", key)
+		}
+
+		for len(list) <= i {
+			list = append(list, nil)
+		}
+
+		elem, err := setNull(list[i], rest)
+		if err != nil {
+			return nil, err
+		}
+
+		list[i] = elem
+
+		return list, nil
+	}
+
+	obj, ok := v.(map[string]any)
+	if !ok {
+		if v != nil {
+			return nil, fmt.Errorf("cannot set %s on a non-object", key)
+		}
+
+		obj = make(map[string]any)
+	}
+
+	elem, err := setNull(obj[key], rest)
+	if err != nil {
+		return nil, err
+	}
+
+	obj[key] = elem
+
+	return obj, nil
+}
+
+func writeFile(w *multipart.Writer, name, path string) error {
+	f, err := os.Open(path) //nolint:gosec // path is provided by the user
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, name, quoteEscaper.Replace(filepath.Base(path))))
+	h.Set("Content-Type", contentType)
+
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return fmt.Errorf("create part: %w", err)
+	}
+
+	if _, err := io.Copy(part, f); err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+)
+
+// Errors returned by servers for automatic persisted queries.
+const (
+	persistedQueryNotFound     = "PERSISTED_QUERY_NOT_FOUND"
+	persistedQueryNotSupported = "PERSISTED_QUERY_NOT_SUPPORTED"
+)
+
+// WithPersistedQueries enables Apollo automatic persisted queries: each
+// request first sends only the SHA-256 hash of the query, and sends the full
+// query when the server does not know the hash yet.
+func WithPersistedQueries() Option {
+	return func(c *Client) {
+		c.persisted = true
+	}
+}
+
+// executePersisted sends the hash of the query, then registers the full query
+// if the server asks for it. The registration is always a POST so that long
+// queries never end up in a URL.
+func (c *Client) executePersisted(ctx context.Context, req *Request) (*Response, error) {
+	first, get := c.firstRequest(req)
+
+	resp, err := c.send(ctx, first, get)
+	if err != nil {
+		return nil, err
+	}
+
+	switch persistedQueryError(resp) {
+	case persistedQueryNotFound:
+		return c.send(ctx, persistedRequest(req, true), false)
+	case persistedQueryNotSupported:
+		return c.send(ctx, req, c.useGET(req))
+	default:
+		return resp, nil
+	}
+}
+
+// persistedRequest returns a copy of req with the persistedQuery extension,
+// without the query text unless withQuery is set.
+func persistedRequest(req *Request, withQuery bool) *Request {
+	sum := sha256.Sum256([]byte(req.Query))
+
+	out := *req
+	out.Extensions = maps.Clone(req.Extensions)
+
+	if out.Extensions == nil {
+		out.Extensions = make(map[string]any, 1)
+	}
+
+	out.Extensions["persistedQuery"] = map[string]any{
+		"version":    1,
+		"sha256Hash": hex.EncodeToString(sum[:]),
+	}
+
+	if !withQuery {
+		out.Query = ""
+	}
+
+	return &out
+}
+
+// persistedQueryError returns the persisted query error code in resp, or "".
+// Older servers only report it in the message.
+func persistedQueryError(resp *Response) string {
+	for _, e := range resp.Errors {
+		switch {
+		case e.Code() == persistedQueryNotFound || e.Message == "PersistedQueryNotFound":
+			return persistedQueryNotFound
+		case e.Code() == persistedQueryNotSupported || e.Message == "PersistedQueryNotSupported":
+			return persistedQueryNotSupported
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// apqServer is a GraphQL stub that understands automatic persisted queries
+// over GET and POST. It records the method and parameters of each request.
+type apqServer struct {
+	mu        sync.Mutex
+	supported bool
+	queries   map[string]string
+	log       []string
+}
+
+func (s *apqServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var req struct {
+		Query      string `json:"query"`
+		Extensions struct {
+			PersistedQuery *struct {
+				Hash string `json:"sha256Hash"`
+			} `json:"persistedQuery"`
+		} `json:"extensions"`
+	}
+
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		if ext := r.URL.Query().Get("extensions"); ext != "" {
+			_ = json.Unmarshal([]byte(ext), &req.Extensions)
+		}
+	} else {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+	}
+
+	entry := r.Method
+	if req.Query != "" {
+		entry += " query"
+	}
+
+	if pq := req.Extensions.PersistedQuery; pq != nil {
+		entry += " hash"
+
+		switch {
+		case !s.supported:
+			s.log = append(s.log, entry)
+			_, _ = io.WriteString(w, `{"errors":[{"message":"PersistedQueryNotSupported"}]}`)
+
+			return
+		case req.Query != "":
+			sum := sha256.Sum256([]byte(req.Query))
+			if hex.EncodeToString(sum[:]) != pq.Hash {
+				http.Error(w, "hash mismatch", http.StatusBadRequest)
+
+				return
+			}
+
+			s.queries[pq.Hash] = req.Query
+		default:
+			if req.Query = s.queries[pq.Hash]; req.Query == "" {
+				s.log = append(s.log, entry)
+				_, _ = io.WriteString(w, `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`)
+
+				return
+			}
+		}
+	}
+
+	s.log = append(s.log, entry)
+
+	data, _ := json.Marshal(map[string]any{"data": map[string]string{"query": req.Query}})
+	_, _ = w.Write(data)
+}
+
+func (s *apqServer) requests() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defer func() { s.log = nil }()
+
+	return strings.Join(s.log, ", ")
+}
+
+func TestExecute_PersistedQueries(t *testing.T) {
+	t.Parallel()
+
+	const query = "{ user { id } }"
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		supported bool
+		query     string
+		first     string
+		second    string
+	}{
+		{
+			name:      "post",
+			opts:      []Option{WithPersistedQueries()},
+			supported: true,
+			query:     query,
+			first:     "POST hash, POST query hash",
+			second:    "POST hash",
+		},
+		{
+			name:      "get",
+			opts:      []Option{WithPersistedQueries(), WithGET()},
+			supported: true,
+			query:     query,
+			first:     "GET hash, POST query hash",
+			second:    "GET hash",
+		},
+		{
+			name:      "mutation over get",
+			opts:      []Option{WithPersistedQueries(), WithGET()},
+			supported: true,
+			query:     "mutation { logout }",
+			first:     "POST hash, POST query hash",
+			second:    "POST hash",
+		},
+		{
+			name:   "not supported",
+			opts:   []Option{WithPersistedQueries()},
+			query:  query,
+			first:  "POST hash, POST query",
+			second: "POST hash, POST query",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stub := &apqServer{supported: tt.supported, queries: make(map[string]string)}
+			srv := httptest.NewServer(stub)
+			defer srv.Close()
+
+			c := New(srv.URL, tt.opts...)
+
+			for _, want := range []string{tt.first, tt.second} {
+				resp, err := c.Execute(context.Background(), &Request{Query: tt.query})
+				if err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+
+				if len(resp.Errors) > 0 || !strings.Contains(string(resp.Data), tt.query) {
+					t.Errorf("Execute() = %s %v", resp.Data, resp.Errors)
+				}
+
+				if got := stub.requests(); got != want {
+					t.Errorf("requests = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExecute_GET(t *testing.T) {
+	t.Parallel()
+
+	var method, rawQuery, preflight string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, rawQuery, preflight = r.Method, r.URL.RawQuery, r.Header.Get("Apollo-Require-Preflight")
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL+"?tenant=a", WithGET())
+
+	tests := []struct {
+		name   string
+		req    *Request
+		method string
+		params map[string]string
+	}{
+		{
+			name:   "query",
+			req:    &Request{Query: "query User($id: ID!) { user(id: $id) { id } }", Variables: map[string]any{"id": "1"}, OperationName: "User"},
+			method: http.MethodGet,
+			params: map[string]string{
+				"tenant":        "a",
+				"query":         "query User($id: ID!) { user(id: $id) { id } }",
+				"variables":     `{"id":"1"}`,
+				"operationName": "User",
+			},
+		},
+		{
+			name:   "named mutation in a document with a query",
+			req:    &Request{Query: "query A { a } mutation B { b }", OperationName: "B"},
+			method: http.MethodPost,
+		},
+		{
+			name:   "invalid",
+			req:    &Request{Query: "{"},
+			method: http.MethodPost,
+		},
+	}
+
+	for _, tt := range tests {
+		if _, err := c.Execute(context.Background(), tt.req); err != nil {
+			t.Fatalf("%s: Execute() error = %v", tt.name, err)
+		}
+
+		if method != tt.method {
+			t.Errorf("%s: method = %s, want %s", tt.name, method, tt.method)
+		}
+
+		if tt.method != http.MethodGet {
+			continue
+		}
+
+		if preflight == "" {
+			t.Errorf("%s: missing preflight header", tt.name)
+		}
+
+		params, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for k, want := range tt.params {
+			if got := params.Get(k); got != want {
+				t.Errorf("%s: param %s = %q, want %q", tt.name, k, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides which failed requests are sent again and how long to
+// wait in between.
+type RetryPolicy struct {
+	// MaxAttempts is the number of tries, including the first. Values below 2
+	// disable retries.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry. It doubles after each
+	// retry, up to MaxDelay, and is randomized by up to half to spread clients.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Statuses are the HTTP statuses to retry, in addition to network errors
+	// and error responses with a Retry-After header.
+	Statuses []int
+	// Mutations also retries mutations, which may then run more than once.
+	// Only queries are retried by default.
+	Mutations bool
+}
+
+// DefaultRetryPolicy returns a policy with three attempts that retries
+// queries on network errors and 429, 502, 503 and 504 responses.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Statuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetry retries failed requests with the policy. It is the same as
+// WithMiddleware(Retry(p)).
+func WithRetry(p RetryPolicy) Option {
+	return WithMiddleware(Retry(p))
+}
+
+// Retry resends requests that fail as described by the policy. A Retry-After
+// header sets the wait instead of the backoff; when it asks for longer than
+// MaxDelay the response is returned as is. Requests whose body cannot be
+// replayed are sent once.
+func Retry(p RetryPolicy) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if p.MaxAttempts < 2 {
+			return next
+		}
+
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !p.allows(req) {
+				return next.RoundTrip(req) //nolint:wrapcheck // middleware passes errors through
+			}
+
+			backoff := p.BaseDelay
+
+			for attempt := 1; ; attempt++ {
+				try := req
+				if attempt > 1 {
+					var err error
+					if try, err = rewind(req); err != nil {
+						return nil, err
+					}
+				}
+
+				resp, err := next.RoundTrip(try)
+				if attempt >= p.MaxAttempts {
+					return resp, err //nolint:wrapcheck // middleware passes errors through
+				}
+
+				wait, retry := p.wait(resp, err, backoff)
+				if !retry {
+					return resp, err //nolint:wrapcheck // middleware passes errors through
+				}
+
+				if resp != nil {
+					_, _ = io.Copy(io.Discard, resp.Body)
+					_ = resp.Body.Close()
+				}
+
+				if err := sleepContext(req.Context(), wait); err != nil {
+					return nil, err
+				}
+
+				backoff = min(2*backoff, max(p.MaxDelay, p.BaseDelay))
+			}
+		})
+	}
+}
+
+// allows reports whether req may be sent more than once.
+func (p RetryPolicy) allows(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	return p.Mutations || IsQuery(req.Context())
+}
+
+// wait returns how long to wait before retrying the outcome of an attempt,
+// and whether to retry at all.
+func (p RetryPolicy) wait(resp *http.Response, err error, backoff time.Duration) (time.Duration, bool) {
+	if err != nil {
+		// A canceled or expired request is not worth another try
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return 0, false
+		}
+
+		return jitter(backoff), true
+	}
+
+	if resp.StatusCode < http.StatusBadRequest {
+		return 0, false
+	}
+
+	if d, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		if p.MaxDelay > 0 && d > p.MaxDelay {
+			return 0, false
+		}
+
+		return d, true
+	}
+
+	if slices.Contains(p.Statuses, resp.StatusCode) {
+		return jitter(backoff), true
+	}
+
+	return 0, false
+}
+
+// jitter returns a random duration between half of d and d.
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+
+	return d/2 + rand.N(d/2+1) //nolint:gosec // jitter does not need a secure source
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP date.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(secs)*time.Second, 0), true
+	}
+
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// rewind returns a copy of req with a fresh body to send it again, and
+// restarts the timing so that it covers the last attempt only.
+func rewind(req *http.Request) (*http.Request, error) {
+	restartTiming(req.Context())
+
+	try := req.Clone(req.Context())
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("rewind body: %w", err)
+		}
+
+		try.Body = body
+	}
+
+	return try, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // context errors are returned as is
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetry is the default policy without the waits.
+func fastRetry() RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 10 * time.Millisecond
+
+	return p
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		statuses   []int
+		retryAfter string
+		policy     func(*RetryPolicy)
+		want       int
+	}{
+		{name: "recovers", query: "{ ok }", statuses: []int{503, 502, 200}, want: 3},
+		{name: "gives up", query: "{ ok }", statuses: []int{503, 503, 503, 200}, want: 3},
+		{name: "max attempts", query: "{ ok }", statuses: []int{503, 200}, policy: func(p *RetryPolicy) { p.MaxAttempts = 1 }, want: 1},
+		{name: "not retried status", query: "{ ok }", statuses: []int{500, 200}, want: 1},
+		{name: "chosen status", query: "{ ok }", statuses: []int{500, 200}, policy: func(p *RetryPolicy) { p.Statuses = []int{500} }, want: 2},
+		{name: "first try", query: "{ ok }", statuses: []int{200}, want: 1},
+		{name: "retry after", query: "{ ok }", statuses: []int{500, 200}, retryAfter: "0", want: 2},
+		{name: "retry after too long", query: "{ ok }", statuses: []int{503, 200}, retryAfter: "60", want: 1},
+		{name: "mutation", query: "mutation { ok }", statuses: []int{503, 200}, want: 1},
+		{name: "forced mutation", query: "mutation { ok }", statuses: []int{503, 200}, policy: func(p *RetryPolicy) { p.Mutations = true }, want: 2},
+		{name: "invalid document", query: "{ ok", statuses: []int{503, 200}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1))
+
+				body, _ := io.ReadAll(r.Body)
+				if !strings.Contains(string(body), "{ ok") {
+					t.Errorf("attempt %d body = %q, want the query", n, body)
+				}
+
+				status := tt.statuses[min(n, len(tt.statuses))-1]
+				if status != http.StatusOK && tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+
+				w.WriteHeader(status)
+				_, _ = io.WriteString(w, `{"data":{"ok":true}}`)
+			}))
+			defer srv.Close()
+
+			p := fastRetry()
+			if tt.policy != nil {
+				tt.policy(&p)
+			}
+
+			c := New(srv.URL, WithRetry(p))
+
+			if _, err := c.Execute(context.Background(), &Request{Query: tt.query}); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if got := int(calls.Load()); got != tt.want {
+				t.Errorf("calls = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetry_NetworkError(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	rt := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"data":{"ok":true}}`)),
+			Request:    r,
+		}, nil
+	})
+
+	c := New("http://example.invalid/graphql", WithTransport(rt), WithRetry(fastRetry()))
+
+	if _, err := c.Execute(context.Background(), &Request{Query: "{ ok }"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestRetry_TimingCoversLastAttempt(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+
+	p := DefaultRetryPolicy()
+	p.BaseDelay = 100 * time.Millisecond
+
+	c := New(srv.URL, WithRetry(p))
+
+	resp, err := c.Execute(context.Background(), &Request{Query: "{ ok }"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// The backoff of at least 50ms must not count as server time
+	if resp.Timing.TTFB >= 50*time.Millisecond {
+		t.Errorf("TTFB = %v, want the last attempt only", resp.Timing.TTFB)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"strings"
+	"time"
+)
+
+// Timing is the timing and size breakdown of a request.
+// Phases that did not happen, such as DNS on a reused connection, are zero.
+type Timing struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// TTFB is the time from the start of the request to the first response byte.
+	TTFB  time.Duration
+	Total time.Duration
+
+	// RequestSize and ResponseSize are body sizes in bytes.
+	RequestSize  int64
+	ResponseSize int64
+	ReusedConn   bool
+
+	// Protocol is the protocol of the response, such as HTTP/1.1 or HTTP/2.0.
+	Protocol string
+	// Encoding is the content encoding of a compressed response, and
+	// EncodedSize its size as received. Both are zero for plain responses.
+	Encoding    string
+	EncodedSize int64
+	// RequestEncoding is the content encoding of a compressed request body,
+	// and RequestEncodedSize its size as sent. Both are zero for plain requests.
+	RequestEncoding    string
+	RequestEncodedSize int64
+}
+
+// String formats the timing on a single line.
+func (t *Timing) String() string {
+	sent := "sent " + formatSize(t.RequestSize)
+	if t.RequestEncoding != "" {
+		sent += " (" + formatSize(t.RequestEncodedSize) + " " + t.RequestEncoding + ")"
+	}
+
+	received := "received " + formatSize(t.ResponseSize)
+	if t.Encoding != "" {
+		received += " (" + formatSize(t.EncodedSize) + " " + t.Encoding + ")"
+	}
+
+	var parts []string
+	if t.Protocol != "" {
+		parts = append(parts, t.Protocol)
+	}
+
+	parts = append(parts,
+		"dns "+formatDuration(t.DNS),
+		"connect "+formatDuration(t.Connect),
+		"tls "+formatDuration(t.TLS),
+		"ttfb "+formatDuration(t.TTFB),
+		"total "+formatDuration(t.Total),
+		sent,
+		received,
+	)
+
+	if t.ReusedConn {
+		parts = append(parts, "(reused connection)")
+	}
+
+	return strings.Join(parts, "  ")
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
+
+func formatSize(n int64) string {
+	const unit = 1024
+
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// tracer records request phases through httptrace.
+type tracer struct {
+	start time.Time
+
+	dnsStart, connectStart, tlsStart time.Time
+
+	timing Timing
+}
+
+func newTracer() *tracer {
+	return &tracer{start: time.Now()}
+}
+
+type tracerKey struct{}
+
+// trace attaches the tracer to ctx, for httptrace and for restartTiming.
+func (t *tracer) trace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(context.WithValue(ctx, tracerKey{}, t), t.clientTrace())
+}
+
+// restartTiming discards the timing recorded so far for the request of ctx,
+// before it is sent again.
+func restartTiming(ctx context.Context) {
+	if t, ok := ctx.Value(tracerKey{}).(*tracer); ok {
+		t.start = time.Now()
+		t.timing = Timing{}
+	}
+}
+
+func (t *tracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.dnsStart = time.Now() },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.timing.DNS = time.Since(t.dnsStart) },
+		ConnectStart: func(string, string) {
+			t.connectStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			t.timing.Connect = time.Since(t.connectStart)
+		},
+		TLSHandshakeStart: func() { t.tlsStart = time.Now() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.timing.TLS = time.Since(t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.timing.ReusedConn = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			t.timing.TTFB = time.Since(t.start)
+		},
+	}
+}
+
+// finish completes the timing once the response body has been read.
+func (t *tracer) finish(proto string, requestSize, responseSize int) *Timing {
+	t.timing.Total = time.Since(t.start)
+	t.timing.Protocol = proto
+	t.timing.RequestSize = int64(requestSize)
+	t.timing.ResponseSize = int64(responseSize)
+
+	return &t.timing
+}
@@ -0,0 +1,71 @@
+package client
+
+import "net/http"
+
+// Wire is the HTTP request the client sends for a GraphQL request, for
+// reproducing it outside of the client.
+type Wire struct {
+	Method string
+	URL    string
+	// Header holds the headers of the request format, such as Content-Type.
+	// The headers set on the client are not included.
+	Header http.Header
+	// Request is the GraphQL request sent as the JSON body, the URL
+	// parameters of a GET or the operations field of an upload. It has no
+	// query text for trusted documents and automatic persisted queries.
+	Request *Request
+	// Form is the multipart form of an upload, in order: the operations and
+	// map fields, then one field per file. It is empty for other requests.
+	Form []FormField
+}
+
+// FormField is a field of a multipart form.
+type FormField struct {
+	Name string
+	// Value is the value of the field, or the path of the file to send.
+	Value string
+	File  bool
+}
+
+// Wire returns the HTTP request the client sends first for req, in the
+// format the client is configured with. With automatic persisted queries it
+// is the request with the hash only. Files are not read.
+func (c *Client) Wire(req *Request) (*Wire, error) {
+	out, get := c.firstRequest(req)
+
+	w := &Wire{
+		Method:  http.MethodPost,
+		URL:     c.endpoint,
+		Header:  make(http.Header),
+		Request: out,
+	}
+
+	if get {
+		target, err := getURL(c.endpoint, out)
+		if err != nil {
+			return nil, err
+		}
+
+		w.Method = http.MethodGet
+		w.URL = target
+		setPreflight(w.Header)
+
+		return w, nil
+	}
+
+	if len(out.Files) == 0 {
+		w.Header.Set("Content-Type", "application/json")
+
+		return w, nil
+	}
+
+	form, err := multipartForm(out)
+	if err != nil {
+		return nil, err
+	}
+
+	w.Form = form
+	setPreflight(w.Header)
+
+	return w, nil
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestWire(t *testing.T) {
+	t.Parallel()
+
+	const endpoint = "https://api.example.com/graphql"
+
+	query := &Request{Query: "{ me { id } }"}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		req        *Request
+		wantMethod string
+		wantQuery  string
+		wantParams url.Values
+		check      func(t *testing.T, w *Wire)
+	}{
+		{
+			name:       "json",
+			req:        query,
+			wantMethod: http.MethodPost,
+			wantQuery:  query.Query,
+		},
+		{
+			name:       "trusted document",
+			req:        &Request{Query: query.Query, DocumentID: "sha256:abc"},
+			wantMethod: http.MethodPost,
+			check: func(t *testing.T, w *Wire) {
+				t.Helper()
+
+				if w.Request.DocumentID != "sha256:abc" {
+					t.Errorf("DocumentID = %q", w.Request.DocumentID)
+				}
+			},
+		},
+		{
+			name:       "persisted query",
+			opts:       []Option{WithPersistedQueries()},
+			req:        query,
+			wantMethod: http.MethodPost,
+			check: func(t *testing.T, w *Wire) {
+				t.Helper()
+
+				if _, ok := w.Request.Extensions["persistedQuery"]; !ok {
+					t.Errorf("Extensions = %v, want persistedQuery", w.Request.Extensions)
+				}
+			},
+		},
+		{
+			name:       "get",
+			opts:       []Option{WithGET()},
+			req:        query,
+			wantMethod: http.MethodGet,
+			wantQuery:  query.Query,
+			wantParams: url.Values{"query": {query.Query}},
+		},
+		{
+			name:       "get mutation",
+			opts:       []Option{WithGET()},
+			req:        &Request{Query: "mutation { logout }"},
+			wantMethod: http.MethodPost,
+			wantQuery:  "mutation { logout }",
+		},
+		{
+			name:       "upload",
+			opts:       []Option{WithPersistedQueries()},
+			req:        &Request{Query: "mutation ($f: Upload!) { up(f: $f) }", Files: map[string]string{"f": "a.png"}},
+			wantMethod: http.MethodPost,
+			wantQuery:  "mutation ($f: Upload!) { up(f: $f) }",
+			check: func(t *testing.T, w *Wire) {
+				t.Helper()
+
+				if len(w.Form) != 3 || w.Form[2] != (FormField{Name: "0", Value: "a.png", File: true}) {
+					t.Errorf("Form = %+v", w.Form)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w, err := New(endpoint, tt.opts...).Wire(tt.req)
+			if err != nil {
+				t.Fatalf("Wire() error = %v", err)
+			}
+
+			if w.Method != tt.wantMethod {
+				t.Errorf("Method = %s, want %s", w.Method, tt.wantMethod)
+			}
+
+			if w.Request.Query != tt.wantQuery {
+				t.Errorf("Query = %q, want %q", w.Request.Query, tt.wantQuery)
+			}
+
+			if tt.wantParams != nil {
+				u, err := url.Parse(w.URL)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got := u.Query(); got.Encode() != tt.wantParams.Encode() {
+					t.Errorf("URL parameters = %v, want %v", got, tt.wantParams)
+				}
+			}
+
+			if tt.check != nil {
+				tt.check(t, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+func newAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Sign in with the OAuth2 settings of a profile",
+		Long: `Sign in with the OAuth2 settings of a profile.
+
+Profiles with an "auth:" section obtain an access token with the
+client_credentials, device or refresh_token flow. Tokens are cached under
+the user config directory and refreshed before they expire, so signing in
+ahead of time is only needed to run the device flow interactively.
+
+Examples:
+  iris auth login --profile staging
+  iris auth logout --profile staging`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "login",
+		Short: "Run the flow and cache the token",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runLogin()
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "logout",
+		Short: "Remove the cached token",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if authProvider == nil {
+				return fmt.Errorf("no auth settings (select a profile with an auth section)")
+			}
+
+			if err := authProvider.Logout(); err != nil {
+				return fmt.Errorf("logout: %w", err)
+			}
+
+			fmt.Println("Logged out.")
+
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+func runLogin() error {
+	if authProvider == nil {
+		return fmt.Errorf("no auth settings (select a profile with an auth section)")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	t, err := authProvider.Login(ctx)
+	if err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+
+	msg := "Logged in."
+	if !t.Expiry.IsZero() {
+		msg = fmt.Sprintf("Logged in; the token expires at %s.", t.Expiry.Local().Format(time.DateTime))
+	}
+
+	fmt.Println(green(msg))
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/filter"
+	"github.com/sivchari/iris/internal/gql"
+	"github.com/sivchari/iris/internal/output"
+)
+
+// runBatch sends the operations of a multi-operation document as one batch
+// and prints each response after a "# <name>" line on stderr.
+func runBatch(c *client.Client, ops []gql.Operation, f output.Format, fl *filter.Filter) error {
+	reqs := make([]*client.Request, len(ops))
+
+	for i, op := range ops {
+		req, err := newRequest(op.Query)
+		if err != nil {
+			return err
+		}
+
+		req.OperationName = op.Name
+		reqs[i] = req
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resps, err := c.ExecuteBatch(ctx, reqs)
+	if err != nil {
+		return fmt.Errorf("execute batch: %w", err)
+	}
+
+	for i, resp := range resps {
+		fmt.Fprintf(os.Stderr, "# %s\n", ops[i].Name)
+
+		// The responses share the timing of the HTTP exchange, shown once
+		if i > 0 {
+			resp.Timing = nil
+		}
+
+		if err := writeResponse(resp, f, fl); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sivchari/iris/internal/bench"
+	"github.com/sivchari/iris/internal/client"
+)
+
+func newBenchCmd() *cobra.Command {
+	var opts bench.Options
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Fire an operation concurrently and report throughput and latency",
+		Long: `Fire an operation concurrently and report throughput and latency.
+
+The run stops after -n requests or after -d, whichever comes first
+(200 requests when neither is given). Errors are split into GraphQL errors,
+returned in a response, and transport errors such as timeouts or non-JSON
+responses.
+
+Examples:
+  iris bench -e https://api.example.com/graphql -f op.graphql -c 20 -n 2000
+  iris bench -e https://api.example.com/graphql -q '{ users { id } }' -c 50 -d 30s
+  iris bench --profile staging -f user.graphql --variables '{"id": "1"}'`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runBench(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&query, "query", "q", "", "Operation to send")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Read the operation from file")
+	cmd.Flags().StringVar(&variables, "variables", "", "Operation variables as a JSON object, or @file to read them from a file")
+	cmd.Flags().IntVarP(&opts.Concurrency, "concurrency", "c", 10, "Number of concurrent workers")
+	cmd.Flags().IntVarP(&opts.Requests, "requests", "n", 0, "Number of requests to send")
+	cmd.Flags().DurationVarP(&opts.Duration, "duration", "d", 0, "Run for this long")
+
+	return cmd
+}
+
+func runBench(opts bench.Options) error {
+	if endpoint == "" {
+		return fmt.Errorf("endpoint required (-e or --profile)")
+	}
+
+	q := getQuery()
+	if q == "" {
+		return fmt.Errorf("operation required (-q, -f or stdin)")
+	}
+
+	req, err := newRequest(q)
+	if err != nil {
+		return err
+	}
+
+	c := benchClient(opts.Concurrency)
+
+	// Ctrl+C stops the run and still prints the report
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Benchmarking %s with %d workers...\n", endpoint, max(opts.Concurrency, 1))
+
+	res := bench.Run(ctx, c, req, opts)
+
+	if err := bench.Write(os.Stdout, res); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+
+	return nil
+}
+
+// benchClient returns a client that keeps one idle connection per worker, so
+// that connections are reused.
+func benchClient(concurrency int) *client.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert // always *http.Transport
+	transport.MaxIdleConnsPerHost = max(concurrency, transport.MaxIdleConnsPerHost)
+
+	// The transport is set first, so that options such as --h2c apply to it
+	opts := append([]client.Option{client.WithTransport(transport)}, clientOptions(nil)...)
+
+	return client.New(endpoint, opts...)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sivchari/iris/internal/bench"
+	"github.com/sivchari/iris/internal/client"
+)
+
+func TestBenchClient_H2C(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"proto":"`+r.Proto+`"}}`)
+	}))
+
+	// The server only speaks h2c, so an HTTP/1.1 request fails
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	srv.Config.Protocols = &protocols
+
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	setGlobal(t, &endpoint, srv.URL)
+	setGlobal(t, &h2c, true)
+
+	res := bench.Run(context.Background(), benchClient(4), &client.Request{Query: "{ proto }"}, bench.Options{Concurrency: 4, Requests: 8})
+
+	if res.TransportErrors != 0 || len(res.Latencies) != 8 {
+		t.Errorf("bench over h2c: %d responses, %d transport errors %v", len(res.Latencies), res.TransportErrors, res.Errors)
+	}
+}
+
+// setGlobal sets a command-line variable for the test and restores it after.
+func setGlobal[T any](t *testing.T, v *T, value T) {
+	t.Helper()
+
+	old := *v
+	*v = value
+
+	t.Cleanup(func() { *v = old })
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/config"
+	"github.com/sivchari/iris/internal/output"
+	"github.com/sivchari/iris/internal/repl"
+)
+
+// profileFlags are the connection flags that a profile can set.
+var profileFlags = []string{
+	"endpoint", "schema", "timeout", "output", "apq", "get", "manifest",
+	"retry", "retry-status", "retry-mutations",
+	"sigv4", "sigv4-region", "sigv4-service", "aws-profile", "cookies",
+	"compress", "h2c",
+}
+
+// connect builds the connection the REPL switches to with "use": the
+// command-line flags, then the settings of the target profile on top, as at
+// startup. A URL target gets the flags only, without any profile settings.
+// The profile is checked before the current settings are dropped, so that a
+// mistyped name keeps them.
+func connect(target string) (*repl.Connection, error) {
+	var p *config.Profile
+
+	if !strings.Contains(target, "://") {
+		var err error
+		if p, err = loadProfile(target); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := resetProfileFlags(cmdFlags); err != nil {
+		return nil, err
+	}
+
+	conn := &repl.Connection{}
+
+	if p == nil {
+		endpoint = target
+	} else {
+		if err := useProfile(p, cmdFlags); err != nil {
+			return nil, err
+		}
+
+		// The profile chosen in the REPL wins over -e and --schema
+		endpoint = p.Endpoint
+		conn.Profile = target
+		conn.Schema = p.Schema
+	}
+
+	f, err := output.ParseFormat(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+
+	if err := loadSigner(); err != nil {
+		return nil, err
+	}
+
+	if err := loadCookies(conn.Profile); err != nil {
+		return nil, err
+	}
+
+	m, err := loadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	conn.Client = client.New(endpoint, clientOptions(nil)...)
+	conn.Manifest = m
+	conn.Output = f
+
+	return conn, nil
+}
+
+// loadProfile returns the config profile name, which must have an endpoint.
+func loadProfile(name string) (*config.Profile, error) {
+	cfg, err := config.Load(config.DefaultPaths()...)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	p, err := cfg.Profile(name)
+	if err != nil {
+		return nil, fmt.Errorf("select profile: %w", err)
+	}
+
+	if p.Endpoint == "" {
+		return nil, fmt.Errorf("profile %s has no endpoint", name)
+	}
+
+	return p, nil
+}
+
+// resetProfileFlags sets the settings a profile may have changed back to
+// their command-line values, and drops the profile headers, auth, signer and
+// cookie jar.
+func resetProfileFlags(flags *pflag.FlagSet) error {
+	profileHeaders = nil
+	authProvider = nil
+	signer = nil
+	cookieJar = nil
+
+	if flags == nil {
+		return nil
+	}
+
+	for _, name := range profileFlags {
+		f := flags.Lookup(name)
+		if f == nil || f.Changed {
+			continue
+		}
+
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			if err := sv.Replace(nil); err != nil {
+				return fmt.Errorf("reset --%s: %w", name, err)
+			}
+
+			continue
+		}
+
+		if err := f.Value.Set(f.DefValue); err != nil {
+			return fmt.Errorf("reset --%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sivchari/iris/internal/output"
+)
+
+const connectConfig = `
+profiles:
+  staging:
+    endpoint: https://staging.example.com/graphql
+    output: yaml
+    headers:
+      X-Env: staging
+  broken:
+    headers:
+      X-Env: broken
+`
+
+func TestConnect(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "iris"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "iris", "config.yaml"), []byte(connectConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	setGlobal(t, &endpoint, endpoint)
+	setGlobal(t, &format, string(output.JSON))
+	setGlobal(t, &profileHeaders, nil)
+	setGlobal(t, &cmdFlags, NewRootCmd().PersistentFlags())
+
+	conn, err := connect("staging")
+	if err != nil {
+		t.Fatalf("connect(staging) error = %v", err)
+	}
+
+	if conn.Output != output.YAML || conn.Client.Endpoint() != "https://staging.example.com/graphql" {
+		t.Errorf("connect(staging) output = %q, endpoint = %s", conn.Output, conn.Client.Endpoint())
+	}
+
+	// A failed switch keeps the settings of the current connection
+	for _, target := range []string{"typo", "broken"} {
+		if _, err := connect(target); err == nil {
+			t.Errorf("connect(%s) error = nil", target)
+		}
+
+		if endpoint != "https://staging.example.com/graphql" || profileHeaders["X-Env"] != "staging" {
+			t.Errorf("connect(%s) changed the settings: endpoint = %s, headers = %v", target, endpoint, profileHeaders)
+		}
+	}
+
+	conn, err = connect("http://localhost:8080/graphql")
+	if err != nil {
+		t.Fatalf("connect(url) error = %v", err)
+	}
+
+	if conn.Output != output.JSON || profileHeaders != nil {
+		t.Errorf("connect(url) output = %q, headers = %v; want the command-line settings", conn.Output, profileHeaders)
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/importer"
+)
+
+func newImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Run requests imported from curl commands or .http files",
+		Long: `Run requests imported from curl commands or .http files.
+
+The endpoint and headers come from the imported request. -e overrides the
+endpoint and -H headers override imported ones.
+
+Examples:
+  iris import curl 'curl https://api.example.com/graphql -H "Authorization: Bearer token" --data-raw "{\"query\":\"{ me { id } }\"}"'
+  iris import http requests.http
+  iris import http requests.http getUser -o table`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "curl <command>",
+		Short: "Run a request copied as a curl command",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req, err := importer.ParseCurl(strings.Join(args, " "))
+			if err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+
+			return runImported(cmd, []*importer.Request{req})
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "http <file> [name]",
+		Short: "Run the GraphQL requests of a JetBrains or VS Code .http file",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reqs, err := importer.LoadHTTPFile(args[0])
+			if err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+
+			if len(args) == 2 {
+				if reqs, err = selectImported(reqs, args[1]); err != nil {
+					return err
+				}
+			}
+
+			return runImported(cmd, reqs)
+		},
+	})
+
+	return cmd
+}
+
+func selectImported(reqs []*importer.Request, name string) ([]*importer.Request, error) {
+	for _, req := range reqs {
+		if req.Name == name {
+			return []*importer.Request{req}, nil
+		}
+	}
+
+	names := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		names = append(names, req.Name)
+	}
+
+	return nil, fmt.Errorf("request not found: %s (available: %s)", name, strings.Join(names, ", "))
+}
+
+// runImported executes the requests in order and prints each response.
+func runImported(cmd *cobra.Command, reqs []*importer.Request) error {
+	f, fl, err := outputOptions()
+	if err != nil {
+		return err
+	}
+
+	for _, req := range reqs {
+		target := req.Endpoint
+		if cmd.Flags().Changed("endpoint") {
+			target = endpoint
+		}
+
+		if len(reqs) > 1 {
+			fmt.Fprintf(os.Stderr, "### %s\n", req.Name)
+		}
+
+		if err := runRequest(client.New(target, clientOptions(req.Headers)...), req.Body, f, fl); err != nil {
+			return fmt.Errorf("%s: %w", req.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/filter"
+	"github.com/sivchari/iris/internal/relay"
+	"github.com/sivchari/iris/internal/trusted"
+)
+
+// runAllPages follows a connection of the query until its last page and writes
+// each node to out as a line of JSON as soon as its page arrives. The filter,
+// if any, is applied to every node.
+func runAllPages(out io.Writer, c *client.Client, q string, fl *filter.Filter) error {
+	s, err := loadSchema(c)
+	if err != nil {
+		return err
+	}
+
+	req, err := newRequest(q)
+	if err != nil {
+		return err
+	}
+
+	p, err := relay.NewPager(s, req, connField)
+	if err != nil {
+		return fmt.Errorf("--all-pages: %w", err)
+	}
+
+	docs, err := newPageDocuments(req)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(out)
+	total := 0
+
+	for req = p.Request(); ; {
+		docs.resolve(req)
+
+		resp, err := executePage(c, req)
+		if err != nil {
+			return fmt.Errorf("page %d: %w", p.Page(), err)
+		}
+
+		nodes, err := p.Nodes(resp.Data)
+		if err != nil {
+			return fmt.Errorf("page %d: %w", p.Page(), err)
+		}
+
+		for _, n := range nodes {
+			if err := writeNode(w, n, fl); err != nil {
+				return err
+			}
+		}
+
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+
+		total += len(nodes)
+
+		if verbose {
+			fmt.Fprintf(os.Stderr, "%s: page %d done, %d nodes so far\n", p.Connection(), p.Page(), total)
+		}
+
+		req, err = p.Next(resp.Data)
+		if errors.Is(err, relay.ErrNoNextPage) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("page %d: %w", p.Page(), err)
+		}
+	}
+}
+
+// pageDocuments sets the document id of each page from the --manifest file.
+type pageDocuments struct {
+	manifest *trusted.Manifest
+	// warned is set once the operation was reported missing from the manifest
+	warned bool
+}
+
+// newPageDocuments returns the documents of the pages of req, the request
+// built for the query, which has already been looked up in the manifest.
+func newPageDocuments(req *client.Request) (*pageDocuments, error) {
+	m, err := loadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pageDocuments{manifest: m, warned: m != nil && req.DocumentID == ""}, nil
+}
+
+// resolve sets the document id of req. Every page sends the same operation,
+// so an unlisted one is reported once.
+func (d *pageDocuments) resolve(req *client.Request) {
+	if d.manifest == nil || lookupDocument(d.manifest, req) || d.warned {
+		return
+	}
+
+	warnUntrusted()
+
+	d.warned = true
+}
+
+// executePage sends one page. GraphQL errors stop the walk, since the
+// remaining pages would be incomplete.
+func executePage(c *client.Client, req *client.Request) (*client.Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resp, err := c.Execute(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("execute query: %w", err)
+	}
+
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("graphql error: %s", resp.Errors[0].Message)
+	}
+
+	return resp, nil
+}
+
+// writeNode writes n, or each value the filter emits for it, as a compact JSON line.
+func writeNode(w *bufio.Writer, n json.RawMessage, fl *filter.Filter) error {
+	values := []json.RawMessage{n}
+
+	if fl != nil {
+		var err error
+		if values, err = fl.Apply(n); err != nil {
+			return err //nolint:wrapcheck // already describes the filter error
+		}
+	}
+
+	for _, v := range values {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, v); err != nil {
+			return fmt.Errorf("encode node: %w", err)
+		}
+
+		buf.WriteByte('\n')
+
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+	}
+
+	return nil
+}
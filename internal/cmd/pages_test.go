@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+const pagesSDL = `
+type Query {
+  users(first: Int, after: String): UserConnection!
+}
+
+type UserConnection {
+  edges: [UserEdge!]!
+  nodes: [User!]!
+  pageInfo: PageInfo!
+}
+
+type UserEdge {
+  node: User!
+}
+
+type User {
+  id: ID!
+}
+
+type PageInfo {
+  endCursor: String
+  hasNextPage: Boolean!
+}
+`
+
+func TestRunAllPages_Manifest(t *testing.T) {
+	q := `query Users($after: String) { users(first: 2, after: $after) { nodes { id } pageInfo { endCursor hasNextPage } } }`
+
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.graphql")
+	manifestPath := filepath.Join(dir, "manifest.json")
+
+	doc, err := json.Marshal(map[string]string{"users-page": q})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(schemaPath, []byte(pagesSDL), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(manifestPath, doc, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []client.Request
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req client.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		got = append(got, req)
+
+		if req.Variables["after"] == nil {
+			_, _ = io.WriteString(w, `{"data":{"users":{"nodes":[{"id":"1"},{"id":"2"}],"pageInfo":{"endCursor":"c2","hasNextPage":true}}}}`)
+
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"data":{"users":{"nodes":[{"id":"3"}],"pageInfo":{"endCursor":"c3","hasNextPage":false}}}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	setGlobal(t, &schema, schemaPath)
+	setGlobal(t, &manifest, manifestPath)
+	setGlobal(t, &timeout, 5*time.Second)
+
+	var out bytes.Buffer
+	if err := runAllPages(&out, client.New(srv.URL), q, nil); err != nil {
+		t.Fatalf("runAllPages() error = %v", err)
+	}
+
+	if want := "{\"id\":\"1\"}\n{\"id\":\"2\"}\n{\"id\":\"3\"}\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("server received %d requests, want 2", len(got))
+	}
+
+	for i, req := range got {
+		if req.DocumentID != "users-page" || req.Query != "" {
+			t.Errorf("page %d sent documentId %q and query %q, want the document id only", i+1, req.DocumentID, req.Query)
+		}
+	}
+}
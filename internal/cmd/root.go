@@ -7,22 +7,74 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vektah/gqlparser/v2/ast"
 
+	"github.com/sivchari/iris/internal/auth"
 	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/config"
+	"github.com/sivchari/iris/internal/cookies"
+	"github.com/sivchari/iris/internal/filter"
 	"github.com/sivchari/iris/internal/gql"
+	"github.com/sivchari/iris/internal/output"
 	"github.com/sivchari/iris/internal/repl"
+	"github.com/sivchari/iris/internal/sigv4"
+	"github.com/sivchari/iris/internal/tracing"
+	"github.com/sivchari/iris/internal/trusted"
 )
 
 var (
-	endpoint string
-	headers  []string
-	query    string
-	file     string
+	endpoint  string
+	headers   []string
+	query     string
+	file      string
+	variables string
+	fileVars  []string
+	watchMode bool
+	interval  = 2 * time.Second
+	allPages  bool
+	connField string
+	profile   string
+	schema    string
+	format    = string(output.JSON)
+	jqFilter  string
+	verbose   bool
+	timeout   = 30 * time.Second
+	apq       bool
+	useGET    bool
+	manifest  string
+	logHTTP   bool
+
+	retries        int
+	retryStatuses  []int
+	retryMutations bool
+
+	sigv4Enabled bool
+	sigv4Region  string
+	sigv4Service string
+	awsProfile   string
+
+	useCookies bool
+	compress   bool
+	h2c        bool
+
+	profileHeaders map[string]string
+	// authProvider obtains tokens for the auth settings of the profile.
+	authProvider *auth.Provider
+	// signer signs requests with AWS SigV4 when --sigv4 is set.
+	signer *sigv4.Signer
+	// cookieJar keeps cookies across requests and runs when --cookies is set.
+	cookieJar *cookies.Jar
+	// cmdFlags are the flags of the command being run.
+	cmdFlags *pflag.FlagSet
 )
 
 // NewRootCmd creates the root command.
@@ -36,16 +88,70 @@ Examples:
   iris -e https://api.example.com/graphql
   iris -e https://api.example.com/graphql -q '{ users { id } }'
   iris -e https://api.example.com/graphql -H "Authorization: Bearer token"
+  iris --profile staging
+  iris -e https://api.example.com/graphql -q '{ users { id email } }' -o table
+  iris -e https://api.example.com/graphql -q '{ users { email } }' --filter '.users[].email'
+  iris -e https://api.example.com/graphql -q '{ users(first: 100) { nodes { id } } }' --all-pages
+  iris -e https://api.example.com/graphql -f upload.graphql --file-var avatar=@./me.png
   echo '{ users { id } }' | iris -e https://api.example.com/graphql`,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			cmdFlags = cmd.Flags()
+
+			if err := applyProfile(cmd); err != nil {
+				return err
+			}
+
+			if err := loadSigner(); err != nil {
+				return err
+			}
+
+			return loadCookies(profile)
+		},
 		RunE: func(_ *cobra.Command, _ []string) error {
 			return run()
 		},
 	}
 
-	cmd.Flags().StringVarP(&endpoint, "endpoint", "e", "", "GraphQL endpoint (required)")
-	cmd.Flags().StringArrayVarP(&headers, "header", "H", nil, "HTTP header")
+	// Connection and output flags are shared with subcommands
+	flags := cmd.PersistentFlags()
+	flags.StringVarP(&endpoint, "endpoint", "e", "", "GraphQL endpoint (required)")
+	flags.StringArrayVarP(&headers, "header", "H", nil, "HTTP header")
+	flags.StringVarP(&profile, "profile", "p", "", "Use a profile from the config file")
+	flags.StringVar(&schema, "schema", "", "Load schema from an SDL file instead of introspection")
+	flags.StringVarP(&format, "output", "o", format, "Output format (json, compact, yaml, table, raw)")
+	flags.StringVar(&jqFilter, "filter", "", "Apply a jq expression (or $.json.path) to the response data")
+	flags.BoolVarP(&verbose, "verbose", "v", false, "Print request timing and traces (to stderr in CLI mode)")
+	flags.DurationVar(&timeout, "timeout", timeout, "Request timeout")
+	flags.BoolVar(&apq, "apq", false, "Send automatic persisted query hashes, sending the full query only when the server asks for it")
+	flags.BoolVar(&useGET, "get", false, "Send queries as HTTP GET requests (mutations are still sent as POST)")
+	flags.StringVar(&manifest, "manifest", "", "Send the document id of operations listed in a trusted documents manifest")
+	flags.BoolVar(&logHTTP, "log-http", false, "Log each HTTP request and its status to stderr")
+	flags.IntVar(&retries, "retry", 0, "Send failed queries up to this many times in total, with backoff (0 disables retries)")
+	flags.IntSliceVar(&retryStatuses, "retry-status", nil, "HTTP statuses to retry (default 429,502,503,504)")
+	flags.BoolVar(&retryMutations, "retry-mutations", false, "Also retry mutations, which may then run more than once")
+	flags.BoolVar(&sigv4Enabled, "sigv4", false, "Sign requests with AWS SigV4, for AppSync and API Gateway IAM auth")
+	flags.StringVar(&sigv4Region, "sigv4-region", "", "AWS region for --sigv4 (default: from the endpoint host)")
+	flags.StringVar(&sigv4Service, "sigv4-service", "", "AWS service for --sigv4 (default: from the endpoint host)")
+	flags.StringVar(&awsProfile, "aws-profile", "", "Shared credentials profile for --sigv4 (default: the AWS_* variables, then AWS_PROFILE)")
+	flags.BoolVar(&useCookies, "cookies", false, "Keep cookies set by responses and send them back, saved per profile (or endpoint host)")
+	flags.BoolVar(&compress, "compress", false, "Gzip request bodies of 1KiB or more (the server must accept Content-Encoding: gzip)")
+	flags.BoolVar(&h2c, "h2c", false, "Send requests to http:// endpoints with HTTP/2 prior knowledge (h2c)")
+
 	cmd.Flags().StringVarP(&query, "query", "q", "", "Execute query")
 	cmd.Flags().StringVarP(&file, "file", "f", "", "Read query from file")
+	cmd.Flags().StringVar(&variables, "variables", "", "Query variables as a JSON object, or @file to read them from a file")
+	cmd.Flags().StringArrayVar(&fileVars, "file-var", nil, "Upload a file as a variable, as name=@path (name may be a path such as input.photos.0)")
+	cmd.Flags().BoolVarP(&watchMode, "watch", "w", false, "Re-run the query on an interval and when the query or variables file changes")
+	cmd.Flags().DurationVar(&interval, "interval", interval, "Interval between runs with --watch (0 runs only on file changes)")
+	cmd.Flags().BoolVar(&allPages, "all-pages", false, "Walk every page of a Relay connection and print its nodes as NDJSON")
+	cmd.Flags().StringVar(&connField, "connection", "", "Connection to page with --all-pages, as a response path (default: the first)")
+
+	cmd.AddCommand(newImportCmd())
+	cmd.AddCommand(newScriptCmd())
+	cmd.AddCommand(newTestCmd())
+	cmd.AddCommand(newSnapshotCmd())
+	cmd.AddCommand(newBenchCmd())
+	cmd.AddCommand(newAuthCmd())
 
 	return cmd
 }
@@ -59,24 +165,314 @@ func Execute() error {
 	return nil
 }
 
+// applyProfile fills settings from the selected config profile.
+// Flags set explicitly on the command line take precedence.
+func applyProfile(cmd *cobra.Command) error {
+	cfg, err := config.Load(config.DefaultPaths()...)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	p, err := cfg.Profile(profile)
+	if err != nil {
+		return fmt.Errorf("select profile: %w", err)
+	}
+
+	if p == nil {
+		return nil
+	}
+
+	return useProfile(p, cmd.Flags())
+}
+
+// useProfile fills the settings from p that are not set on the command line.
+func useProfile(p *config.Profile, flags *pflag.FlagSet) error {
+	if !flags.Changed("endpoint") && p.Endpoint != "" {
+		endpoint = p.Endpoint
+	}
+
+	if !flags.Changed("schema") && p.Schema != "" {
+		schema = p.Schema
+	}
+
+	if !flags.Changed("timeout") && p.Timeout > 0 {
+		timeout = p.Timeout
+	}
+
+	if !flags.Changed("output") && p.Output != "" {
+		format = p.Output
+	}
+
+	if !flags.Changed("apq") && p.APQ {
+		apq = true
+	}
+
+	if !flags.Changed("get") && p.GET {
+		useGET = true
+	}
+
+	if !flags.Changed("manifest") && p.Manifest != "" {
+		manifest = p.Manifest
+	}
+
+	if r := p.Retry; r != nil {
+		if !flags.Changed("retry") && r.Attempts > 0 {
+			retries = r.Attempts
+		}
+
+		if !flags.Changed("retry-status") && len(r.Statuses) > 0 {
+			retryStatuses = r.Statuses
+		}
+
+		if !flags.Changed("retry-mutations") && r.Mutations {
+			retryMutations = true
+		}
+	}
+
+	if sv := p.SigV4; sv != nil {
+		if !flags.Changed("sigv4") {
+			sigv4Enabled = true
+		}
+
+		if !flags.Changed("sigv4-region") && sv.Region != "" {
+			sigv4Region = sv.Region
+		}
+
+		if !flags.Changed("sigv4-service") && sv.Service != "" {
+			sigv4Service = sv.Service
+		}
+
+		if !flags.Changed("aws-profile") && sv.AWSProfile != "" {
+			awsProfile = sv.AWSProfile
+		}
+	}
+
+	if !flags.Changed("cookies") && p.Cookies {
+		useCookies = true
+	}
+
+	if !flags.Changed("compress") && p.Compress {
+		compress = true
+	}
+
+	if !flags.Changed("h2c") && p.H2C {
+		h2c = true
+	}
+
+	profileHeaders = p.Headers
+
+	if p.Auth != nil {
+		var err error
+		if authProvider, err = auth.New(p.Auth, auth.WithCacheDir(config.Subdir("tokens"))); err != nil {
+			return fmt.Errorf("select profile: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadSigner creates the SigV4 signer when signing is enabled.
+func loadSigner() error {
+	if !sigv4Enabled {
+		return nil
+	}
+
+	creds, err := sigv4.LoadCredentials(awsProfile)
+	if err != nil {
+		return fmt.Errorf("--sigv4: %w", err)
+	}
+
+	if u, err := url.Parse(endpoint); err == nil && endpoint != "" && (sigv4Region == "" || sigv4Service == "") {
+		if region, service := sigv4.Infer(u.Hostname()); region == "" || service == "" {
+			return fmt.Errorf("--sigv4: cannot infer the region and service of %s (use --sigv4-region and --sigv4-service)", u.Host)
+		}
+	}
+
+	signer = sigv4.New(creds, sigv4Region, sigv4Service)
+
+	return nil
+}
+
+// loadCookies opens the cookie jar of the profile name, or of the endpoint
+// host without a profile, when cookies are kept.
+func loadCookies(name string) error {
+	if !useCookies {
+		return nil
+	}
+
+	if name == "" {
+		// A missing or invalid endpoint is reported when it is used
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			return nil
+		}
+
+		name = u.Host
+	}
+
+	var path string
+	if dir := config.Subdir("cookies"); dir != "" {
+		path = filepath.Join(dir, cookieFileName(name)+".json")
+	}
+
+	var err error
+	if cookieJar, err = cookies.Open(path); err != nil {
+		return fmt.Errorf("--cookies: %w", err)
+	}
+
+	return nil
+}
+
+// cookieFileName replaces the characters of name that are not safe in a file
+// name, such as the colon of a host with a port.
+func cookieFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+
+		return '_'
+	}, name)
+}
+
 func run() error {
 	if endpoint == "" {
-		return fmt.Errorf("endpoint required (-e)")
+		return fmt.Errorf("endpoint required (-e or --profile)")
+	}
+
+	f, fl, err := outputOptions()
+	if err != nil {
+		return err
 	}
 
-	// Create client
-	c := client.New(endpoint, parseHeaders()...)
+	c := client.New(endpoint, clientOptions(nil)...)
+
+	q := getQuery()
+	if watchMode {
+		if q == "" {
+			return fmt.Errorf("--watch requires a query (-q or -f)")
+		}
+
+		return runWatch(c, q, f, fl)
+	}
+
+	if allPages {
+		if q == "" {
+			return fmt.Errorf("--all-pages requires a query (-q or -f)")
+		}
+
+		return runAllPages(os.Stdout, c, q, fl)
+	}
 
 	// CLI mode or REPL mode
-	if q := getQuery(); q != "" {
-		return runQuery(c, q)
+	if q != "" {
+		// Every operation of a multi-operation document is sent in one batch
+		if ops, err := gql.SplitOperations(q); err == nil && len(ops) > 1 {
+			return runBatch(c, ops, f, fl)
+		}
+
+		req, err := newRequest(q)
+		if err != nil {
+			return err
+		}
+
+		return runRequest(c, req, f, fl)
 	}
 
-	return runREPL(c)
+	return runREPL(c, f)
 }
 
-func parseHeaders() []client.Option {
-	var opts []client.Option
+// outputOptions parses the --output and --filter flags.
+func outputOptions() (output.Format, *filter.Filter, error) {
+	f, err := output.ParseFormat(format)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid --output: %w", err)
+	}
+
+	var fl *filter.Filter
+	if jqFilter != "" {
+		if fl, err = filter.Compile(jqFilter); err != nil {
+			return "", nil, fmt.Errorf("invalid --filter: %w", err)
+		}
+	}
+
+	return f, fl, nil
+}
+
+// envHeaderPrefix is the prefix of environment variables sent as headers,
+// such as IRIS_HEADER_X_API_KEY for X-Api-Key.
+const envHeaderPrefix = "IRIS_HEADER_"
+
+// compressMinSize is the smallest request body gzipped with --compress.
+const compressMinSize = 1024
+
+// clientOptions returns the client options for the connection flags, with
+// the headers of base between the profile headers and the -H flags.
+func clientOptions(base map[string]string) []client.Option {
+	opts := append(parseHeaders(base), client.WithTimeout(timeout), client.WithMiddleware(client.EnvHeaders(envHeaderPrefix)))
+
+	// The logging is inside the retries, so that each attempt is logged
+	if retries > 1 {
+		policy := client.DefaultRetryPolicy()
+		policy.MaxAttempts = retries
+		policy.Mutations = retryMutations
+
+		if len(retryStatuses) > 0 {
+			policy.Statuses = retryStatuses
+		}
+
+		opts = append(opts, client.WithRetry(policy))
+	}
+
+	// Compressed bodies are signed as they are sent
+	if compress {
+		opts = append(opts, client.WithMiddleware(client.CompressRequests(compressMinSize)))
+	}
+
+	// Each attempt is signed again, with the headers it is sent with
+	if signer != nil {
+		opts = append(opts, client.WithMiddleware(signer.Middleware()))
+	}
+
+	if logHTTP {
+		opts = append(opts, client.WithMiddleware(client.Logging(os.Stderr)))
+	}
+
+	if apq {
+		opts = append(opts, client.WithPersistedQueries())
+	}
+
+	if useGET {
+		opts = append(opts, client.WithGET())
+	}
+
+	if authProvider != nil {
+		opts = append(opts, client.WithAuth(authProvider))
+	}
+
+	if cookieJar != nil {
+		opts = append(opts, client.WithCookieJar(cookieJar))
+	}
+
+	if h2c {
+		opts = append(opts, client.WithH2C())
+	}
+
+	return opts
+}
+
+// parseHeaders returns the client options for the profile headers, then base,
+// then the -H flags, so that later sources override earlier ones.
+func parseHeaders(base map[string]string) []client.Option {
+	opts := make([]client.Option, 0, len(profileHeaders)+len(base)+len(headers))
+
+	for k, v := range profileHeaders {
+		opts = append(opts, client.WithHeader(k, v))
+	}
+
+	for k, v := range base {
+		opts = append(opts, client.WithHeader(k, v))
+	}
 
 	for _, h := range headers {
 		if parts := strings.SplitN(h, ":", 2); len(parts) == 2 {
@@ -114,57 +510,205 @@ func getQuery() string {
 	return ""
 }
 
-func runQuery(c *client.Client, q string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// newRequest builds a request for q with the --variables, --file-var and --manifest flags.
+func newRequest(q string) (*client.Request, error) {
+	req := &client.Request{Query: q}
+
+	for _, fv := range fileVars {
+		name, path, ok := strings.Cut(fv, "=")
+		if path = strings.TrimPrefix(path, "@"); !ok || name == "" || path == "" {
+			return nil, fmt.Errorf("invalid --file-var %q: want name=@path", fv)
+		}
+
+		if req.Files == nil {
+			req.Files = make(map[string]string)
+		}
+
+		req.Files[name] = path
+	}
+
+	if err := resolveDocument(req); err != nil {
+		return nil, err
+	}
+
+	if variables == "" {
+		return req, nil
+	}
+
+	data := []byte(variables)
+
+	if path, ok := strings.CutPrefix(variables, "@"); ok {
+		var err error
+		if data, err = os.ReadFile(path); err != nil { //nolint:gosec // file path from user flag
+			return nil, fmt.Errorf("read variables: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(data, &req.Variables); err != nil {
+		return nil, fmt.Errorf("invalid --variables: %w", err)
+	}
+
+	return req, nil
+}
+
+// resolveDocument sets the document id of req from the --manifest file,
+// warning when the operation is not listed.
+func resolveDocument(req *client.Request) error {
+	m, err := loadManifest()
+	if err != nil || m == nil {
+		return err
+	}
+
+	if !lookupDocument(m, req) {
+		warnUntrusted()
+	}
+
+	return nil
+}
+
+// lookupDocument sets the document id of req from m, and reports whether the
+// operation is listed.
+func lookupDocument(m *trusted.Manifest, req *client.Request) bool {
+	id, ok := m.Lookup(req.Query)
+	if ok {
+		req.DocumentID = id
+	}
+
+	return ok
+}
+
+func warnUntrusted() {
+	fmt.Fprintln(os.Stderr, "warning: the operation is not in the trusted documents manifest; sending the query text")
+}
+
+// loadManifest loads the --manifest file, or returns nil without one.
+func loadManifest() (*trusted.Manifest, error) {
+	if manifest == "" {
+		return nil, nil //nolint:nilnil // no manifest configured
+	}
+
+	m, err := trusted.Load(manifest)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // already describes the manifest
+	}
+
+	return m, nil
+}
+
+func runRequest(c *client.Client, req *client.Request, f output.Format, fl *filter.Filter) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	resp, err := c.Execute(ctx, &client.Request{Query: q})
+	resp, err := c.Execute(ctx, req)
 	if err != nil {
 		return fmt.Errorf("execute query: %w", err)
 	}
 
-	// Output
-	var out any
+	return writeResponse(resp, f, fl)
+}
+
+// writeResponse prints a response in the output format, after the diagnostics with --verbose.
+func writeResponse(resp *client.Response, f output.Format, fl *filter.Filter) error {
+	var err error
 
-	if len(resp.Errors) > 0 {
-		outMap := map[string]any{"errors": resp.Errors}
+	if verbose {
+		printDiagnostics(resp)
+	}
+
+	if fl != nil {
+		return writeFiltered(resp, f, fl)
+	}
 
-		if resp.Data != nil {
-			var data any
-			if err := json.Unmarshal(resp.Data, &data); err == nil {
-				outMap["data"] = data
+	data := resp.Data
+	if len(data) == 0 {
+		data = json.RawMessage("null")
+	}
+
+	if len(resp.Errors) > 0 {
+		switch f {
+		case output.JSON, output.Compact, output.YAML:
+			// Keep errors alongside data in structured formats
+			data, err = json.Marshal(struct {
+				Errors []client.Error  `json:"errors"`
+				Data   json.RawMessage `json:"data,omitempty"`
+			}{resp.Errors, resp.Data})
+			if err != nil {
+				return fmt.Errorf("encode output: %w", err)
+			}
+		case output.Table, output.Raw:
+			for _, e := range resp.Errors {
+				fmt.Fprintf(os.Stderr, "error: %s\n", e.Message)
 			}
 		}
+	}
 
-		out = outMap
-	} else {
-		_ = json.Unmarshal(resp.Data, &out)
+	if err := output.Write(os.Stdout, f, data); err != nil {
+		return fmt.Errorf("write output: %w", err)
 	}
 
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
+	return nil
+}
 
-	if err := enc.Encode(out); err != nil {
-		return fmt.Errorf("encode output: %w", err)
+// printDiagnostics writes the request timing and any resolver trace to stderr.
+func printDiagnostics(resp *client.Response) {
+	if resp.Timing != nil {
+		fmt.Fprintln(os.Stderr, resp.Timing.String())
+	}
+
+	t, err := tracing.FromExtensions(resp.Extensions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "trace: %v\n", err)
+
+		return
+	}
+
+	if t != nil {
+		_ = tracing.Render(os.Stderr, t)
+	}
+}
+
+// writeFiltered prints each value emitted by the filter. Errors go to stderr.
+func writeFiltered(resp *client.Response, f output.Format, fl *filter.Filter) error {
+	for _, e := range resp.Errors {
+		fmt.Fprintf(os.Stderr, "error: %s\n", e.Message)
+	}
+
+	results, err := fl.Apply(resp.Data)
+	if err != nil {
+		return err //nolint:wrapcheck // already describes the filter error
+	}
+
+	for _, v := range results {
+		if err := output.Write(os.Stdout, f, v); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
 	}
 
 	return nil
 }
 
-func runREPL(c *client.Client) error {
+func runREPL(c *client.Client, f output.Format) error {
 	fmt.Printf("Connecting to %s...\n", endpoint)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	schema, err := gql.LoadSchemaFromIntrospection(ctx, c)
+	s, err := loadSchema(c)
 	if err != nil {
-		return fmt.Errorf("introspection failed: %w", err)
+		return err
 	}
 
-	fmt.Printf("Loaded %d types.\n\n", len(schema.Types))
+	fmt.Printf("Loaded %d types.\n\n", len(s.Types))
 
-	r := repl.New(c, schema)
+	m, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	r := repl.New(c, s,
+		repl.WithProfile(profile),
+		repl.WithOutput(f),
+		repl.WithTiming(verbose),
+		repl.WithManifest(m),
+		repl.WithConnect(connect),
+	)
 	defer func() { _ = r.Close() }()
 
 	if err := r.Run(); err != nil {
@@ -173,3 +717,15 @@ func runREPL(c *client.Client) error {
 
 	return nil
 }
+
+func loadSchema(c *client.Client) (*ast.Schema, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	s, err := gql.LoadSchema(ctx, c, schema)
+	if err != nil {
+		return nil, fmt.Errorf("load schema: %w", err)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import "testing"
+
+func TestNewRequest_FileVar(t *testing.T) {
+	setGlobal(t, &manifest, "")
+	setGlobal(t, &variables, "")
+
+	tests := []struct {
+		fileVar string
+		wantErr bool
+	}{
+		{fileVar: "avatar=@./me.png"},
+		{fileVar: "avatar=./me.png"},
+		{fileVar: "avatar=@", wantErr: true},
+		{fileVar: "avatar=", wantErr: true},
+		{fileVar: "=@./me.png", wantErr: true},
+		{fileVar: "avatar", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		setGlobal(t, &fileVars, []string{tt.fileVar})
+
+		req, err := newRequest("mutation($avatar: Upload!) { upload(file: $avatar) }")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("newRequest() with --file-var %s error = %v, wantErr %v", tt.fileVar, err, tt.wantErr)
+
+			continue
+		}
+
+		if err == nil && req.Files["avatar"] != "./me.png" {
+			t.Errorf("newRequest() with --file-var %s Files = %v", tt.fileVar, req.Files)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/repl"
+)
+
+func newScriptCmd() *cobra.Command {
+	var vars []string
+
+	cmd := &cobra.Command{
+		Use:   "script <file>",
+		Short: "Run a script of REPL commands and operations non-interactively",
+		Long: `Run a script of REPL commands and operations non-interactively.
+
+Each line is a REPL command; GraphQL operations may span several lines.
+"let name = <jq expression>" captures a value from the last response and
+"{{name}}" inserts it into later lines. The command exits non-zero when a
+statement fails or a response contains GraphQL errors.
+
+Example flow.iris:
+  # Create a user and list their posts
+  mutation { createUser(name: "{{name}}") { id } }
+  let userId = .createUser.id
+  { posts(authorId: "{{userId}}") { title } } | .posts[].title
+
+Examples:
+  iris script flow.iris -e https://api.example.com/graphql
+  iris script flow.iris --profile staging --var name=alice`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runScript(args[0], vars)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&vars, "var", nil, "Define a variable as name=value")
+
+	return cmd
+}
+
+func runScript(path string, vars []string) error {
+	if endpoint == "" {
+		return fmt.Errorf("endpoint required (-e or --profile)")
+	}
+
+	defined := make(map[string]string, len(vars))
+
+	for _, v := range vars {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok {
+			return fmt.Errorf("invalid --var: %s (want name=value)", v)
+		}
+
+		defined[name] = value
+	}
+
+	f, _, err := outputOptions()
+	if err != nil {
+		return err
+	}
+
+	c := client.New(endpoint, clientOptions(nil)...)
+
+	s, err := loadSchema(c)
+	if err != nil {
+		return err
+	}
+
+	m, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	r := repl.New(c, s,
+		repl.WithProfile(profile),
+		repl.WithOutput(f),
+		repl.WithTiming(verbose),
+		repl.WithVariables(defined),
+		repl.WithManifest(m),
+		repl.WithConnect(connect),
+	)
+	defer func() { _ = r.Close() }()
+
+	if err := r.RunScript(path); err != nil {
+		return fmt.Errorf("script: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/jsondiff"
+	"github.com/sivchari/iris/internal/snapshot"
+	"github.com/sivchari/iris/internal/testsuite"
+)
+
+func newSnapshotCmd() *cobra.Command {
+	var opts snapshot.Options
+
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Record and verify golden-file snapshots of responses",
+		Long: `Record and verify golden-file snapshots of responses.
+
+Snapshots use the operations of the test files run by "iris test". Responses
+are normalized (sorted keys, volatile values masked) and stored in
+__snapshots__/<file>/<test>.json next to each test file. Mask paths are jq
+expressions or JSONPaths on the response data, set with "mask:" in a test file
+or test, or with --mask.
+
+Examples:
+  iris snapshot record ./tests/... -e https://api.example.com/graphql --mask '.users[].createdAt'
+  iris snapshot verify ./tests/... --profile staging`,
+	}
+
+	cmd.PersistentFlags().StringArrayVar(&opts.Mask, "mask", nil, "Mask the value at this path in every snapshot")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "record <path>...",
+		Short: "Save normalized responses to golden files",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runSnapshots(args, opts, snapshot.Record)
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "verify <path>...",
+		Short: "Compare responses to the golden files",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runSnapshots(args, opts, snapshot.Verify)
+		},
+	})
+
+	return cmd
+}
+
+type snapshotFunc func(context.Context, *client.Client, []*testsuite.File, snapshot.Options, func(*snapshot.Result)) []*snapshot.Result
+
+func runSnapshots(patterns []string, opts snapshot.Options, fn snapshotFunc) error {
+	if endpoint == "" {
+		return fmt.Errorf("endpoint required (-e or --profile)")
+	}
+
+	files, err := testsuite.Load(patterns...)
+	if err != nil {
+		return fmt.Errorf("load tests: %w", err)
+	}
+
+	c := client.New(endpoint, clientOptions(nil)...)
+
+	results := fn(context.Background(), c, files, opts, printSnapshotResult)
+
+	failed := 0
+
+	for _, res := range results {
+		if !res.OK() {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d snapshots failed", failed, len(results))
+	}
+
+	return nil
+}
+
+func printSnapshotResult(res *snapshot.Result) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	label := fmt.Sprintf("%-9s", res.Status)
+	status := green(label)
+
+	switch res.Status {
+	case snapshot.Drifted, snapshot.Missing, snapshot.Failed:
+		status = red(label)
+	case snapshot.Created, snapshot.Updated:
+		status = yellow(label)
+	case snapshot.Unchanged, snapshot.Matched:
+	}
+
+	fmt.Printf("%s  %s  %s\n", status, res.Name, gray(res.Path))
+
+	switch {
+	case res.Err != nil:
+		fmt.Printf("      %s\n", red(res.Err))
+	case res.Status == snapshot.Missing:
+		fmt.Println("      no snapshot; run 'iris snapshot record' first")
+	}
+
+	for _, c := range res.Changes {
+		switch c.Kind {
+		case jsondiff.Added:
+			fmt.Println("      " + green("+ "+c.Path+": "+jsondiff.Format(c.New)))
+		case jsondiff.Removed:
+			fmt.Println("      " + red("- "+c.Path+": "+jsondiff.Format(c.Old)))
+		case jsondiff.Changed:
+			fmt.Printf("      %s %s: %s -> %s\n", yellow("~"), c.Path, red(jsondiff.Format(c.Old)), green(jsondiff.Format(c.New)))
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/testsuite"
+)
+
+func newTestCmd() *cobra.Command {
+	var junit string
+
+	cmd := &cobra.Command{
+		Use:   "test <path>...",
+		Short: "Run assertion-based tests against an endpoint",
+		Long: `Run assertion-based tests against an endpoint.
+
+A path is a YAML test file, a directory, or a directory followed by "/..."
+to include subdirectories. The command exits non-zero when a test fails.
+
+Example tests/users.yaml:
+  tests:
+    - name: fetch a user
+      query: query GetUser($id: ID!) { user(id: $id) { id name } }
+      variables: { id: "1" }
+      expect:
+        data: { user: { name: Alice } }
+        paths: { "$.user.id": "1" }
+        maxLatency: 500ms
+    - name: requires auth
+      query: "{ me { id } }"
+      expect:
+        errorCode: UNAUTHENTICATED
+
+Examples:
+  iris test ./tests/... -e https://api.example.com/graphql
+  iris test ./tests/... --profile staging --junit report.xml`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runTests(args, junit)
+		},
+	}
+
+	cmd.Flags().StringVar(&junit, "junit", "", "Write a JUnit XML report to this file")
+
+	return cmd
+}
+
+func runTests(patterns []string, junit string) error {
+	if endpoint == "" {
+		return fmt.Errorf("endpoint required (-e or --profile)")
+	}
+
+	files, err := testsuite.Load(patterns...)
+	if err != nil {
+		return fmt.Errorf("load tests: %w", err)
+	}
+
+	c := client.New(endpoint, clientOptions(nil)...)
+
+	report := testsuite.Run(context.Background(), c, files, printResult)
+
+	if junit != "" {
+		if err := writeJUnit(junit, report); err != nil {
+			return err
+		}
+	}
+
+	failed := report.Failed()
+	fmt.Printf("\n%d passed, %d failed in %s\n",
+		len(report.Results)-failed, failed, report.Duration.Round(time.Millisecond))
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tests failed", failed, len(report.Results))
+	}
+
+	return nil
+}
+
+func printResult(res *testsuite.Result) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	status := green("PASS")
+	if !res.Passed() {
+		status = red("FAIL")
+	}
+
+	fmt.Printf("%s  %s  %s %s\n", status, gray(res.File), res.Name, gray(res.Duration.Round(time.Millisecond)))
+
+	if res.Err != nil {
+		fmt.Printf("      %s\n", red(res.Err))
+	}
+
+	for _, f := range res.Failures {
+		fmt.Printf("      %s\n", f)
+	}
+}
+
+func writeJUnit(path string, report *testsuite.Report) error {
+	f, err := os.Create(path) //nolint:gosec // report path from user flag
+	if err != nil {
+		return fmt.Errorf("create junit report: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := testsuite.WriteJUnit(f, report); err != nil {
+		return fmt.Errorf("write junit report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/filter"
+	"github.com/sivchari/iris/internal/output"
+	"github.com/sivchari/iris/internal/watch"
+)
+
+// runWatch re-runs the query until interrupted. The query and variables files
+// are re-read for every run.
+func runWatch(c *client.Client, q string, f output.Format, fl *filter.Filter) error {
+	title := strings.Join(strings.Fields(q), " ")
+
+	var files []string
+
+	if file != "" {
+		title = file
+		files = append(files, file)
+	}
+
+	if path, ok := strings.CutPrefix(variables, "@"); ok {
+		files = append(files, path)
+	}
+
+	req, err := newRequest(q)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range req.Files {
+		files = append(files, path)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	w := &watch.Watcher{
+		Title:    title,
+		Interval: interval,
+		Files:    files,
+		Execute: func(ctx context.Context) (*client.Response, error) {
+			if file != "" {
+				data, err := os.ReadFile(file) //nolint:gosec // file path from user flag
+				if err != nil {
+					return nil, fmt.Errorf("read query: %w", err)
+				}
+
+				q = string(data)
+			}
+
+			req, err := newRequest(q)
+			if err != nil {
+				return nil, err
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			resp, err := c.Execute(ctx, req)
+			if err != nil {
+				return nil, fmt.Errorf("execute query: %w", err)
+			}
+
+			return resp, nil
+		},
+		Print: func(resp *client.Response) error {
+			return writeResponse(resp, f, fl)
+		},
+	}
+
+	if err := w.Run(ctx); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	return nil
+}
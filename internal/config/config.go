@@ -0,0 +1,235 @@
+// Package config loads iris configuration files and endpoint profiles.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sivchari/iris/internal/auth"
+)
+
+// LocalFile is the project-local configuration file name.
+const LocalFile = ".iris.yaml"
+
+// Config is the merged iris configuration.
+type Config struct {
+	// Default is the profile used when none is selected explicitly.
+	Default  string              `yaml:"default"`
+	Profiles map[string]*Profile `yaml:"profiles"`
+	// Redact lists additional header names treated as secrets when exporting requests.
+	Redact []string `yaml:"redact"`
+}
+
+// Profile is a named set of connection settings.
+type Profile struct {
+	Endpoint string            `yaml:"endpoint"`
+	Headers  map[string]string `yaml:"headers"`
+	Timeout  time.Duration     `yaml:"timeout"`
+	// Schema is a path to an SDL file. Introspection is used when empty.
+	Schema string `yaml:"schema"`
+	Output string `yaml:"output"`
+	// APQ sends automatic persisted query hashes.
+	APQ bool `yaml:"apq"`
+	// GET sends queries as HTTP GET requests.
+	GET bool `yaml:"get"`
+	// Manifest is a path to a trusted documents manifest.
+	Manifest string `yaml:"manifest"`
+	// Auth obtains an access token with an OAuth2 flow instead of a static header.
+	Auth *auth.Config `yaml:"auth"`
+	// Retry resends failed requests.
+	Retry *Retry `yaml:"retry"`
+	// SigV4 signs requests with AWS Signature Version 4.
+	SigV4 *SigV4 `yaml:"sigv4"`
+	// Cookies keeps the cookies set by responses, saved per profile.
+	Cookies bool `yaml:"cookies"`
+	// Compress gzips large request bodies.
+	Compress bool `yaml:"compress"`
+	// H2C sends requests to http:// URLs with HTTP/2 prior knowledge.
+	H2C bool `yaml:"h2c"`
+}
+
+// SigV4 configures AWS request signing. Empty region and service are
+// inferred from the endpoint host.
+type SigV4 struct {
+	Region  string `yaml:"region"`
+	Service string `yaml:"service"`
+	// AWSProfile is the shared credentials profile. The AWS_* environment
+	// variables are used when it is empty.
+	AWSProfile string `yaml:"aws_profile"`
+}
+
+// Retry configures retries of failed requests.
+type Retry struct {
+	// Attempts is the number of tries, including the first.
+	Attempts int `yaml:"attempts"`
+	// Statuses are the HTTP statuses to retry, replacing the defaults.
+	Statuses []int `yaml:"statuses"`
+	// Mutations also retries mutations.
+	Mutations bool `yaml:"mutations"`
+}
+
+// Dir returns the iris user config directory: $XDG_CONFIG_HOME/iris, or
+// ~/.config/iris on every platform. It returns "" when neither is known.
+func Dir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "iris")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".config", "iris")
+}
+
+// Subdir returns a directory under Dir for state such as cached tokens, or ""
+// when Dir is unknown.
+func Subdir(name string) string {
+	dir := Dir()
+	if dir == "" {
+		return ""
+	}
+
+	return filepath.Join(dir, name)
+}
+
+// DefaultPaths returns the configuration files read by default, in order
+// of increasing precedence: the user config file, then the project-local file.
+func DefaultPaths() []string {
+	var paths []string
+
+	if dir := Dir(); dir != "" {
+		paths = append(paths, filepath.Join(dir, "config.yaml"))
+	}
+
+	return append(paths, LocalFile)
+}
+
+// Load reads and merges the given configuration files.
+// Missing files are skipped; profiles in later files replace earlier ones with the same name.
+func Load(paths ...string) (*Config, error) {
+	cfg := &Config{Profiles: make(map[string]*Profile)}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path) //nolint:gosec // config path is trusted
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+
+		var file Config
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+
+		if file.Default != "" {
+			cfg.Default = file.Default
+		}
+
+		cfg.Redact = append(cfg.Redact, file.Redact...)
+
+		for name, p := range file.Profiles {
+			if p == nil {
+				p = &Profile{}
+			}
+
+			cfg.Profiles[name] = p.expand()
+		}
+	}
+
+	return cfg, nil
+}
+
+// Profile returns the named profile, or the default profile when name is empty.
+// It returns nil without error when no name is given and no default is configured.
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.Default
+	}
+
+	if name == "" {
+		return nil, nil //nolint:nilnil // no profile selected
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile not found: %s", name)
+	}
+
+	return p, nil
+}
+
+// Names returns the sorted profile names.
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// expand interpolates environment variables in the profile values.
+func (p *Profile) expand() *Profile {
+	out := &Profile{
+		Endpoint: os.ExpandEnv(p.Endpoint),
+		Headers:  make(map[string]string, len(p.Headers)),
+		Timeout:  p.Timeout,
+		Schema:   os.ExpandEnv(p.Schema),
+		Output:   os.ExpandEnv(p.Output),
+		APQ:      p.APQ,
+		GET:      p.GET,
+		Manifest: os.ExpandEnv(p.Manifest),
+		Retry:    p.Retry,
+		Cookies:  p.Cookies,
+		Compress: p.Compress,
+		H2C:      p.H2C,
+	}
+
+	for k, v := range p.Headers {
+		out.Headers[k] = os.ExpandEnv(v)
+	}
+
+	if p.SigV4 != nil {
+		out.SigV4 = &SigV4{
+			Region:     os.ExpandEnv(p.SigV4.Region),
+			Service:    os.ExpandEnv(p.SigV4.Service),
+			AWSProfile: os.ExpandEnv(p.SigV4.AWSProfile),
+		}
+	}
+
+	if p.Auth != nil {
+		a := *p.Auth
+		a.Flow = os.ExpandEnv(a.Flow)
+		a.Issuer = os.ExpandEnv(a.Issuer)
+		a.TokenURL = os.ExpandEnv(a.TokenURL)
+		a.DeviceAuthURL = os.ExpandEnv(a.DeviceAuthURL)
+		a.ClientID = os.ExpandEnv(a.ClientID)
+		a.ClientSecret = os.ExpandEnv(a.ClientSecret)
+		a.Audience = os.ExpandEnv(a.Audience)
+		a.RefreshToken = os.ExpandEnv(a.RefreshToken)
+
+		a.Scopes = make([]string, len(p.Auth.Scopes))
+		for i, scope := range p.Auth.Scopes {
+			a.Scopes[i] = os.ExpandEnv(scope)
+		}
+
+		out.Auth = &a
+	}
+
+	return out
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoad_Merge(t *testing.T) {
+	dir := t.TempDir()
+
+	global := writeFile(t, dir, "config.yaml", `
+default: local
+profiles:
+  local:
+    endpoint: http://localhost:8080/query
+  staging:
+    endpoint: https://staging.example.com/graphql
+    timeout: 10s
+`)
+	local := writeFile(t, dir, ".iris.yaml", `
+profiles:
+  staging:
+    endpoint: https://staging.internal/graphql
+    schema: ./schema.graphql
+`)
+
+	cfg, err := Load(global, local, filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := cfg.Names(); len(got) != 2 || got[0] != "local" || got[1] != "staging" {
+		t.Errorf("Names() = %v, want [local staging]", got)
+	}
+
+	p, err := cfg.Profile("")
+	if err != nil {
+		t.Fatalf("Profile() error = %v", err)
+	}
+
+	if p.Endpoint != "http://localhost:8080/query" {
+		t.Errorf("default profile endpoint = %q", p.Endpoint)
+	}
+
+	p, err = cfg.Profile("staging")
+	if err != nil {
+		t.Fatalf("Profile() error = %v", err)
+	}
+
+	if p.Endpoint != "https://staging.internal/graphql" {
+		t.Errorf("Endpoint = %q, want local override", p.Endpoint)
+	}
+
+	if p.Schema != "./schema.graphql" {
+		t.Errorf("Schema = %q", p.Schema)
+	}
+
+	if p.Timeout != 0 {
+		t.Errorf("Timeout = %v, want profile to be replaced entirely", p.Timeout)
+	}
+}
+
+func TestLoad_EnvInterpolation(t *testing.T) {
+	t.Setenv("IRIS_TEST_TOKEN", "secret")
+	t.Setenv("IRIS_TEST_HOST", "api.example.com")
+	t.Setenv("IRIS_TEST_REGION", "eu-west-1")
+	t.Setenv("IRIS_TEST_AWS_PROFILE", "staging")
+
+	path := writeFile(t, t.TempDir(), "config.yaml", `
+profiles:
+  prod:
+    endpoint: https://${IRIS_TEST_HOST}/graphql
+    timeout: 5s
+    headers:
+      Authorization: Bearer ${IRIS_TEST_TOKEN}
+    sigv4:
+      region: ${IRIS_TEST_REGION}
+      service: appsync
+      aws_profile: ${IRIS_TEST_AWS_PROFILE}
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	p, err := cfg.Profile("prod")
+	if err != nil {
+		t.Fatalf("Profile() error = %v", err)
+	}
+
+	if p.Endpoint != "https://api.example.com/graphql" {
+		t.Errorf("Endpoint = %q", p.Endpoint)
+	}
+
+	if got := p.Headers["Authorization"]; got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+	}
+
+	if p.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want 5s", p.Timeout)
+	}
+
+	if sv := p.SigV4; sv == nil || sv.Region != "eu-west-1" || sv.Service != "appsync" || sv.AWSProfile != "staging" {
+		t.Errorf("SigV4 = %+v, want the variables expanded", sv)
+	}
+}
+
+func TestLoad_Auth(t *testing.T) {
+	t.Setenv("IRIS_TEST_SECRET", "s3cret")
+
+	path := writeFile(t, t.TempDir(), "config.yaml", `
+profiles:
+  prod:
+    endpoint: https://api.example.com/graphql
+    auth:
+      flow: client_credentials
+      issuer: https://login.example.com
+      client_id: iris
+      client_secret: ${IRIS_TEST_SECRET}
+      scopes: [read, write]
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	a := cfg.Profiles["prod"].Auth
+	if a == nil {
+		t.Fatal("Auth = nil")
+	}
+
+	if a.Flow != "client_credentials" || a.Issuer != "https://login.example.com" || a.ClientID != "iris" {
+		t.Errorf("Auth = %+v", a)
+	}
+
+	if a.ClientSecret != "s3cret" {
+		t.Errorf("ClientSecret = %q, want %q", a.ClientSecret, "s3cret")
+	}
+
+	if len(a.Scopes) != 2 || a.Scopes[1] != "write" {
+		t.Errorf("Scopes = %v", a.Scopes)
+	}
+}
+
+func TestDir(t *testing.T) {
+	t.Setenv("HOME", "/home/iris")
+
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+
+	if got := Dir(); got != filepath.Join("/xdg", "iris") {
+		t.Errorf("Dir() = %q with XDG_CONFIG_HOME", got)
+	}
+
+	// Relative values are ignored, as the XDG spec requires
+	t.Setenv("XDG_CONFIG_HOME", "relative")
+
+	if got := Dir(); got != filepath.Join("/home/iris", ".config", "iris") {
+		t.Errorf("Dir() = %q, want ~/.config/iris", got)
+	}
+}
+
+func TestProfile_NotFound(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Profiles: map[string]*Profile{}}
+
+	if _, err := cfg.Profile("missing"); err == nil {
+		t.Error("Profile() expected error for unknown profile")
+	}
+
+	p, err := cfg.Profile("")
+	if err != nil || p != nil {
+		t.Errorf("Profile(\"\") = %v, %v; want nil, nil", p, err)
+	}
+}
@@ -0,0 +1,256 @@
+// Package cookies provides a cookie jar that is saved to a file, so that a
+// session cookie set by a login mutation is sent with later requests and
+// kept across runs.
+package cookies
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cookie is a stored cookie and the URL of the response that set it.
+type Cookie struct {
+	URL      string    `json:"url"`
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Expires  time.Time `json:"expires,omitzero"`
+	Secure   bool      `json:"secure,omitempty"`
+	HTTPOnly bool      `json:"httpOnly,omitempty"`
+}
+
+// Jar is an http.CookieJar that writes its cookies to a file each time a
+// response sets one. Cookies are matched to requests by net/http/cookiejar.
+type Jar struct {
+	path string
+
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	cookies map[string]Cookie
+}
+
+// Open returns the jar saved at path, or an empty jar when the file does not
+// exist yet. An empty path keeps the cookies in memory only.
+func Open(path string) (*Jar, error) {
+	j := &Jar{path: path}
+	j.reset()
+
+	if path == "" {
+		return j, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return j, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read cookies: %w", err)
+	}
+
+	var saved []Cookie
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("parse cookies %s: %w", path, err)
+	}
+
+	now := time.Now()
+
+	for _, c := range saved {
+		if !c.Expires.IsZero() && c.Expires.Before(now) {
+			continue
+		}
+
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			continue
+		}
+
+		j.set(u, c)
+	}
+
+	return j, nil
+}
+
+// Path returns the file the jar is saved to.
+func (j *Jar) Path() string {
+	return j.path
+}
+
+// SetCookies implements http.CookieJar. The jar is saved on a best effort
+// basis; a failure only means the cookies are not kept for the next run.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+
+	for _, hc := range cookies {
+		c := Cookie{
+			URL:      u.Scheme + "://" + u.Host,
+			Name:     hc.Name,
+			Value:    hc.Value,
+			Domain:   strings.TrimPrefix(strings.ToLower(hc.Domain), "."),
+			Path:     hc.Path,
+			Expires:  hc.Expires,
+			Secure:   hc.Secure,
+			HTTPOnly: hc.HttpOnly,
+		}
+
+		switch {
+		case hc.MaxAge < 0:
+			c.Expires = time.Unix(1, 0)
+		case hc.MaxAge > 0:
+			c.Expires = now.Add(time.Duration(hc.MaxAge) * time.Second)
+		}
+
+		if c.Domain == "" {
+			c.Domain = u.Hostname()
+		}
+
+		if c.Path == "" {
+			c.Path = defaultPath(u.Path)
+		}
+
+		j.set(u, c)
+	}
+
+	_ = j.save()
+}
+
+// Cookies implements http.CookieJar.
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.jar.Cookies(u)
+}
+
+// List returns the cookies that have not expired, by domain, path and name.
+func (j *Jar) List() []Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	list := make([]Cookie, 0, len(j.cookies))
+
+	for _, c := range j.cookies {
+		if c.Expires.IsZero() || c.Expires.After(now) {
+			list = append(list, c)
+		}
+	}
+
+	sort.Slice(list, func(a, b int) bool {
+		return key(list[a]) < key(list[b])
+	})
+
+	return list
+}
+
+// Clear removes every cookie and deletes the file.
+func (j *Jar) Clear() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.reset()
+
+	if j.path == "" {
+		return nil
+	}
+
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove cookies: %w", err)
+	}
+
+	return nil
+}
+
+func (j *Jar) reset() {
+	// A nil public suffix list never fails
+	j.jar, _ = cookiejar.New(nil)
+	j.cookies = make(map[string]Cookie)
+}
+
+// set stores c, or deletes it when it has expired.
+func (j *Jar) set(u *url.URL, c Cookie) {
+	hc := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Expires:  c.Expires,
+		Secure:   c.Secure,
+		HttpOnly: c.HTTPOnly,
+	}
+
+	// Host-only cookies are stored without a domain, as they were received
+	if c.Domain != u.Hostname() {
+		hc.Domain = c.Domain
+	}
+
+	j.jar.SetCookies(u, []*http.Cookie{hc})
+
+	if !c.Expires.IsZero() && !c.Expires.After(time.Now()) {
+		delete(j.cookies, key(c))
+
+		return
+	}
+
+	j.cookies[key(c)] = c
+}
+
+// save writes the cookies to the file, readable by the user only.
+func (j *Jar) save() error {
+	if j.path == "" {
+		return nil
+	}
+
+	list := make([]Cookie, 0, len(j.cookies))
+	for _, c := range j.cookies {
+		list = append(list, c)
+	}
+
+	sort.Slice(list, func(a, b int) bool {
+		return key(list[a]) < key(list[b])
+	})
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal cookies: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return fmt.Errorf("create cookie directory: %w", err)
+	}
+
+	if err := os.WriteFile(j.path, data, 0o600); err != nil {
+		return fmt.Errorf("write cookies: %w", err)
+	}
+
+	return nil
+}
+
+func key(c Cookie) string {
+	return c.Domain + ";" + c.Path + ";" + c.Name
+}
+
+// defaultPath returns the default path of a cookie set by a response to a
+// request for path, as in RFC 6265 section 5.1.4.
+func defaultPath(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return "/"
+	}
+
+	return path[:i]
+}
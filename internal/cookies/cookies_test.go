@@ -0,0 +1,144 @@
+package cookies
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u
+}
+
+func TestJar_Persist(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cookies", "staging.json")
+	u := mustParse(t, "https://api.example.com/graphql")
+
+	jar, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "s1", HttpOnly: true},
+		{Name: "pref", Value: "dark", Domain: ".example.com", Path: "/", MaxAge: 3600},
+	})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("cookie file not written: %v", err)
+	}
+
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("cookie file mode = %o, want 600", perm)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	got := map[string]string{}
+	for _, c := range reopened.Cookies(u) {
+		got[c.Name] = c.Value
+	}
+
+	if got["session"] != "s1" || got["pref"] != "dark" {
+		t.Errorf("Cookies() after reopening = %v", got)
+	}
+
+	// Domain cookies match other hosts of the domain, host-only cookies do not
+	other := reopened.Cookies(mustParse(t, "https://www.example.com/"))
+	if len(other) != 1 || other[0].Name != "pref" {
+		t.Errorf("Cookies() for another host = %v, want pref only", other)
+	}
+
+	list := reopened.List()
+	if len(list) != 2 || list[0].Domain != "api.example.com" || !list[0].HTTPOnly || list[1].Domain != "example.com" {
+		t.Errorf("List() = %+v", list)
+	}
+}
+
+func TestJar_Expire(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	u := mustParse(t, "http://localhost:8080/graphql")
+
+	jar, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "s1", Path: "/"},
+		{Name: "old", Value: "x", Path: "/", Expires: time.Now().Add(-time.Hour)},
+	})
+
+	// A logout response deletes the cookie with Max-Age
+	jar.SetCookies(u, []*http.Cookie{{Name: "session", Path: "/", MaxAge: -1}})
+
+	if got := jar.Cookies(u); len(got) != 0 {
+		t.Errorf("Cookies() = %v, want none", got)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if got := reopened.List(); len(got) != 0 {
+		t.Errorf("List() after reopening = %v, want none", got)
+	}
+}
+
+func TestJar_Clear(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	u := mustParse(t, "http://localhost:8080/graphql")
+
+	jar, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "s1"}})
+
+	if err := jar.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+
+	if got := jar.Cookies(u); len(got) != 0 {
+		t.Errorf("Cookies() after Clear() = %v", got)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("cookie file still exists: %v", err)
+	}
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path); err == nil {
+		t.Error("Open() error = nil, want a parse error")
+	}
+}
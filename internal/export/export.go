@@ -0,0 +1,376 @@
+// Package export renders GraphQL requests as commands and code snippets.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+// Format is an export format.
+type Format string
+
+// Supported export formats.
+const (
+	Curl   Format = "curl"
+	HTTPie Format = "httpie"
+	Go     Format = "go"
+)
+
+// Redacted replaces the values of redacted headers.
+const Redacted = "REDACTED"
+
+// DefaultRedactHeaders are the headers treated as secrets by default.
+var DefaultRedactHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// Request is an HTTP GraphQL request to export.
+type Request struct {
+	// Headers are the headers set on the client.
+	Headers map[string]string
+	// Wire is the HTTP request the client sends, as returned by Client.Wire.
+	Wire *client.Wire
+}
+
+// Options configures an export.
+type Options struct {
+	// Redact lists header names whose values are replaced. Matching is case-insensitive.
+	Redact []string
+}
+
+// ParseFormat parses an export format name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case Curl, HTTPie, Go:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown export format: %s (use: curl, httpie, go)", s)
+	}
+}
+
+// Render renders the request in the given format.
+func Render(f Format, req *Request, opts Options) (string, error) {
+	headers := req.headerList(opts.Redact)
+
+	switch f {
+	case Curl:
+		return curl(req, headers)
+	case HTTPie:
+		return httpie(req, headers)
+	case Go:
+		return goSnippet(req, headers)
+	default:
+		return "", fmt.Errorf("unknown export format: %s", f)
+	}
+}
+
+type header struct {
+	name, value string
+}
+
+// headerList returns the headers of the request format, such as
+// Content-Type, followed by the client headers sorted by name.
+func (r *Request) headerList(redact []string) []header {
+	var headers []header
+
+	for _, name := range slices.Sorted(maps.Keys(r.Wire.Header)) {
+		headers = append(headers, header{name, r.Wire.Header.Get(name)})
+	}
+
+	names := make([]string, 0, len(r.Headers))
+	for name := range r.Headers {
+		if !strings.EqualFold(name, "Content-Type") {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := r.Headers[name]
+		if isRedacted(name, redact) {
+			value = Redacted
+		}
+
+		headers = append(headers, header{name, value})
+	}
+
+	return headers
+}
+
+func isRedacted(name string, redact []string) bool {
+	for _, r := range redact {
+		if strings.EqualFold(name, r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func curl(req *Request, headers []header) (string, error) {
+	w := req.Wire
+
+	lines := []string{"curl -X " + w.Method + " " + shellQuote(w.URL)}
+	for _, h := range headers {
+		lines = append(lines, "-H "+shellQuote(h.name+": "+h.value))
+	}
+
+	switch {
+	case len(w.Form) > 0:
+		// --form-string sends values starting with @ or < as they are
+		for _, f := range w.Form {
+			if f.File {
+				lines = append(lines, "-F "+shellQuote(f.Name+"=@"+curlFilename(f.Value)))
+			} else {
+				lines = append(lines, "--form-string "+shellQuote(f.Name+"="+f.Value))
+			}
+		}
+	case w.Method == http.MethodGet:
+		// The request is in the URL
+	default:
+		body, err := json.Marshal(w.Request)
+		if err != nil {
+			return "", fmt.Errorf("marshal: %w", err)
+		}
+
+		lines = append(lines, "--data-raw "+shellQuote(string(body)))
+	}
+
+	return strings.Join(lines, " \\\n  ") + "\n", nil
+}
+
+// curlFilename quotes a file path for curl -F when it contains characters
+// that curl would otherwise read as field options.
+func curlFilename(path string) string {
+	if !strings.ContainsAny(path, `;,"\`) {
+		return path
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(path) + `"`
+}
+
+func httpie(req *Request, headers []header) (string, error) {
+	w := req.Wire
+
+	command := "http "
+	if len(w.Form) > 0 {
+		command += "--multipart "
+	}
+
+	lines := []string{command + w.Method + " " + shellQuote(w.URL)}
+	for _, h := range headers {
+		lines = append(lines, shellQuote(h.name+":"+h.value))
+	}
+
+	if len(w.Form) > 0 {
+		// HTTPie sends name=value as a form field and name@path as a file
+		for _, f := range w.Form {
+			if f.File {
+				lines = append(lines, shellQuote(f.Name+"@"+f.Value))
+			} else {
+				lines = append(lines, shellQuote(f.Name+"="+f.Value))
+			}
+		}
+
+		return strings.Join(lines, " \\\n  ") + "\n", nil
+	}
+
+	if w.Method == http.MethodGet {
+		return strings.Join(lines, " \\\n  ") + "\n", nil
+	}
+
+	items, err := httpieItems(w.Request)
+	if err != nil {
+		return "", err
+	}
+
+	lines = append(lines, items...)
+
+	return strings.Join(lines, " \\\n  ") + "\n", nil
+}
+
+// httpieItems returns the request items HTTPie sends as a JSON object:
+// name=string, name:=raw JSON.
+func httpieItems(body *client.Request) ([]string, error) {
+	var items []string
+
+	if body.Query != "" {
+		items = append(items, shellQuote("query="+body.Query))
+	}
+
+	if body.DocumentID != "" {
+		items = append(items, shellQuote("documentId="+body.DocumentID))
+	}
+
+	for _, field := range []struct {
+		name  string
+		value map[string]any
+	}{{"variables", body.Variables}, {"extensions", body.Extensions}} {
+		if len(field.value) == 0 {
+			continue
+		}
+
+		data, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s: %w", field.name, err)
+		}
+
+		items = append(items, shellQuote(field.name+":="+string(data)))
+	}
+
+	if body.OperationName != "" {
+		items = append(items, shellQuote("operationName="+body.OperationName))
+	}
+
+	return items, nil
+}
+
+func goSnippet(req *Request, headers []header) (string, error) {
+	w := req.Wire
+
+	imports := []string{"fmt", "io", "net/http"}
+
+	switch {
+	case len(w.Form) > 0:
+		imports = append(imports, "bytes", "mime/multipart", "os", "path/filepath")
+	case w.Method != http.MethodGet:
+		imports = append(imports, "strings")
+	}
+
+	sort.Strings(imports)
+
+	var sb strings.Builder
+
+	sb.WriteString("package main\n\nimport (\n")
+
+	for _, imp := range imports {
+		fmt.Fprintf(&sb, "%q\n", imp)
+	}
+
+	sb.WriteString(")\n\nfunc main() {\n")
+
+	body := "body"
+
+	switch {
+	case len(w.Form) > 0:
+		goForm(&sb, w.Form)
+	case w.Method == http.MethodGet:
+		body = "nil"
+	default:
+		data, err := json.Marshal(w.Request)
+		if err != nil {
+			return "", fmt.Errorf("marshal: %w", err)
+		}
+
+		fmt.Fprintf(&sb, "body := strings.NewReader(%s)\n\n", goString(string(data)))
+	}
+
+	fmt.Fprintf(&sb, "req, err := http.NewRequest(%s, %s, %s)\n", goMethod(w.Method), strconv.Quote(w.URL), body)
+	sb.WriteString("if err != nil {\npanic(err)\n}\n\n")
+
+	if len(w.Form) > 0 {
+		sb.WriteString("req.Header.Set(\"Content-Type\", mw.FormDataContentType())\n")
+	}
+
+	for _, h := range headers {
+		fmt.Fprintf(&sb, "req.Header.Set(%s, %s)\n", strconv.Quote(h.name), strconv.Quote(h.value))
+	}
+
+	sb.WriteString(`
+resp, err := http.DefaultClient.Do(req)
+if err != nil {
+panic(err)
+}
+defer resp.Body.Close()
+
+out, err := io.ReadAll(resp.Body)
+if err != nil {
+panic(err)
+}
+
+fmt.Println(string(out))
+}
+`)
+
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return "", fmt.Errorf("format: %w", err)
+	}
+
+	return string(src), nil
+}
+
+// goForm writes the code that builds the multipart body of an upload.
+func goForm(sb *strings.Builder, form []client.FormField) {
+	sb.WriteString(`body := new(bytes.Buffer)
+mw := multipart.NewWriter(body)
+
+attach := func(name, path string) {
+f, err := os.Open(path)
+if err != nil {
+panic(err)
+}
+defer f.Close()
+
+part, err := mw.CreateFormFile(name, filepath.Base(path))
+if err != nil {
+panic(err)
+}
+
+if _, err := io.Copy(part, f); err != nil {
+panic(err)
+}
+}
+
+`)
+
+	for _, f := range form {
+		if f.File {
+			fmt.Fprintf(sb, "attach(%s, %s)\n", strconv.Quote(f.Name), strconv.Quote(f.Value))
+		} else {
+			fmt.Fprintf(sb, "if err := mw.WriteField(%s, %s); err != nil {\npanic(err)\n}\n", strconv.Quote(f.Name), goString(f.Value))
+		}
+	}
+
+	sb.WriteString("\nif err := mw.Close(); err != nil {\npanic(err)\n}\n\n")
+}
+
+// goMethod returns the net/http constant of an HTTP method.
+func goMethod(method string) string {
+	switch method {
+	case http.MethodGet:
+		return "http.MethodGet"
+	case http.MethodPost:
+		return "http.MethodPost"
+	default:
+		return strconv.Quote(method)
+	}
+}
+
+// goString returns s as a Go raw string literal when possible, otherwise as a quoted string.
+func goString(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+
+	return "`" + s + "`"
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,292 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+func testRequest(t *testing.T, body *client.Request, opts ...client.Option) *Request {
+	t.Helper()
+
+	if body == nil {
+		body = &client.Request{
+			Query:         "query GetUser($id: ID!) { user(id: $id) { name } }",
+			Variables:     map[string]any{"id": "1"},
+			OperationName: "GetUser",
+		}
+	}
+
+	wire, err := client.New("https://api.example.com/graphql", opts...).Wire(body)
+	if err != nil {
+		t.Fatalf("Wire() error = %v", err)
+	}
+
+	return &Request{
+		Headers: map[string]string{
+			"Authorization": "Bearer secret",
+			"X-Team":        "o'brien",
+		},
+		Wire: wire,
+	}
+}
+
+func uploadRequest() *client.Request {
+	return &client.Request{
+		Query:     "mutation ($file: Upload!) { upload(file: $file) { id } }",
+		Variables: map[string]any{"file": nil},
+		Files:     map[string]string{"file": "./avatar.png"},
+	}
+}
+
+func TestRender_Curl(t *testing.T) {
+	t.Parallel()
+
+	got, err := Render(Curl, testRequest(t, nil), Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := `curl -X POST 'https://api.example.com/graphql' \
+  -H 'Content-Type: application/json' \
+  -H 'Authorization: Bearer secret' \
+  -H 'X-Team: o'\''brien' \
+  --data-raw '{"query":"query GetUser($id: ID!) { user(id: $id) { name } }","variables":{"id":"1"},"operationName":"GetUser"}'
+`
+	if got != want {
+		t.Errorf("Render() = %s\nwant %s", got, want)
+	}
+}
+
+func TestRender_HTTPie(t *testing.T) {
+	t.Parallel()
+
+	got, err := Render(HTTPie, testRequest(t, nil), Options{Redact: []string{"authorization"}})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := `http POST 'https://api.example.com/graphql' \
+  'Content-Type:application/json' \
+  'Authorization:REDACTED' \
+  'X-Team:o'\''brien' \
+  'query=query GetUser($id: ID!) { user(id: $id) { name } }' \
+  'variables:={"id":"1"}' \
+  'operationName=GetUser'
+`
+	if got != want {
+		t.Errorf("Render() = %s\nwant %s", got, want)
+	}
+}
+
+func TestRender_Go(t *testing.T) {
+	t.Parallel()
+
+	req := testRequest(t, &client.Request{Query: "{ `weird` }"})
+
+	got, err := Render(Go, req, Options{Redact: DefaultRedactHeaders})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"package main\n",
+		`body := strings.NewReader("{\"query\":\"{ ` + "`weird`" + ` }\"`,
+		`http.NewRequest(http.MethodPost, "https://api.example.com/graphql", body)`,
+		`req.Header.Set("Authorization", "REDACTED")`,
+		`req.Header.Set("X-Team", "o'brien")`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() = %s\nshould contain %s", got, want)
+		}
+	}
+}
+
+func TestRender_Upload(t *testing.T) {
+	t.Parallel()
+
+	operations := `{"query":"mutation ($file: Upload!) { upload(file: $file) { id } }","variables":{"file":null}}`
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			format: Curl,
+			want: `curl -X POST 'https://api.example.com/graphql' \
+  -H 'Apollo-Require-Preflight: true' \
+  -H 'Authorization: Bearer secret' \
+  -H 'X-Team: o'\''brien' \
+  --form-string 'operations=` + operations + `' \
+  --form-string 'map={"0":["variables.file"]}' \
+  -F '0=@./avatar.png'
+`,
+		},
+		{
+			format: HTTPie,
+			want: `http --multipart POST 'https://api.example.com/graphql' \
+  'Apollo-Require-Preflight:true' \
+  'Authorization:Bearer secret' \
+  'X-Team:o'\''brien' \
+  'operations=` + operations + `' \
+  'map={"0":["variables.file"]}' \
+  '0@./avatar.png'
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Render(tt.format, testRequest(t, uploadRequest()), Options{})
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Render() = %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_GoUpload(t *testing.T) {
+	t.Parallel()
+
+	got, err := Render(Go, testRequest(t, uploadRequest()), Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`"mime/multipart"`,
+		"if err := mw.WriteField(\"map\", `{\"0\":[\"variables.file\"]}`); err != nil {",
+		`attach("0", "./avatar.png")`,
+		`req.Header.Set("Content-Type", mw.FormDataContentType())`,
+		`req.Header.Set("Apollo-Require-Preflight", "true")`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() = %s\nshould contain %s", got, want)
+		}
+	}
+
+	if strings.Contains(got, `"strings"`) {
+		t.Errorf("Render() = %s\nshould not import strings", got)
+	}
+}
+
+func TestRender_WireFormat(t *testing.T) {
+	t.Parallel()
+
+	query := &client.Request{Query: "{ me { id } }"}
+
+	tests := []struct {
+		name   string
+		format Format
+		body   *client.Request
+		opts   []client.Option
+		want   []string
+		absent []string
+	}{
+		{
+			name:   "trusted document",
+			format: Curl,
+			body:   &client.Request{Query: query.Query, DocumentID: "sha256:abc"},
+			want:   []string{`--data-raw '{"documentId":"sha256:abc"}'`},
+			absent: []string{"me { id }"},
+		},
+		{
+			name:   "trusted document httpie",
+			format: HTTPie,
+			body:   &client.Request{Query: query.Query, DocumentID: "sha256:abc"},
+			want:   []string{`'documentId=sha256:abc'`},
+			absent: []string{"query="},
+		},
+		{
+			name:   "persisted query",
+			format: HTTPie,
+			body:   query,
+			opts:   []client.Option{client.WithPersistedQueries()},
+			want:   []string{`'extensions:={"persistedQuery":{"sha256Hash":"`},
+			absent: []string{"query="},
+		},
+		{
+			name:   "get",
+			format: Curl,
+			body:   query,
+			opts:   []client.Option{client.WithGET()},
+			want: []string{
+				`curl -X GET 'https://api.example.com/graphql?query=%7B+me+%7B+id+%7D+%7D'`,
+				`-H 'Apollo-Require-Preflight: true'`,
+			},
+			absent: []string{"--data-raw", "Content-Type"},
+		},
+		{
+			name:   "get httpie",
+			format: HTTPie,
+			body:   query,
+			opts:   []client.Option{client.WithGET()},
+			want:   []string{`http GET 'https://api.example.com/graphql?query=%7B+me+%7B+id+%7D+%7D'`},
+			absent: []string{"'query="},
+		},
+		{
+			name:   "get go",
+			format: Go,
+			body:   query,
+			opts:   []client.Option{client.WithGET()},
+			want:   []string{`http.NewRequest(http.MethodGet, "https://api.example.com/graphql?query=%7B+me+%7B+id+%7D+%7D", nil)`},
+			absent: []string{`"strings"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Render(tt.format, testRequest(t, tt.body, tt.opts...), Options{})
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Render() = %s\nshould contain %s", got, want)
+				}
+			}
+
+			for _, absent := range tt.absent {
+				if strings.Contains(got, absent) {
+					t.Errorf("Render() = %s\nshould not contain %s", got, absent)
+				}
+			}
+		})
+	}
+}
+
+func TestCurlFilename(t *testing.T) {
+	t.Parallel()
+
+	for path, want := range map[string]string{
+		"./a.png":      "./a.png",
+		"a;type=x.png": `"a;type=x.png"`,
+		`dir\"b".png`:  `"dir\\\"b\".png"`,
+	} {
+		if got := curlFilename(path); got != want {
+			t.Errorf("curlFilename(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	if f, err := ParseFormat("CURL"); err != nil || f != Curl {
+		t.Errorf("ParseFormat(CURL) = %q, %v", f, err)
+	}
+
+	if _, err := ParseFormat("wget"); err == nil {
+		t.Error("ParseFormat(wget) expected error")
+	}
+}
@@ -0,0 +1,132 @@
+// Package filter applies jq-style expressions to GraphQL response data.
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/itchyny/gojq"
+)
+
+// Filter is a compiled jq expression.
+type Filter struct {
+	expr string
+	code *gojq.Code
+}
+
+// Compile parses a jq expression. Simple JSONPath expressions starting
+// with '$' (for example $.users[*].email) are accepted as well.
+func Compile(expr string) (*Filter, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "$") {
+		expr = fromJSONPath(expr)
+	}
+
+	q, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parse filter: %w", err)
+	}
+
+	code, err := gojq.Compile(q)
+	if err != nil {
+		return nil, fmt.Errorf("compile filter: %w", err)
+	}
+
+	return &Filter{expr: expr, code: code}, nil
+}
+
+// String returns the jq expression.
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// Apply runs the filter on the JSON document data and returns
+// each emitted value as a JSON document.
+func (f *Filter) Apply(data []byte) ([]json.RawMessage, error) {
+	var input any
+
+	if len(bytes.TrimSpace(data)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+
+		if err := dec.Decode(&input); err != nil {
+			return nil, fmt.Errorf("decode: %w", err)
+		}
+	}
+
+	var results []json.RawMessage
+
+	iter := f.code.Run(input)
+
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+
+		if err, ok := v.(error); ok {
+			return nil, fmt.Errorf("filter: %w", err)
+		}
+
+		out, err := gojq.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("encode: %w", err)
+		}
+
+		results = append(results, out)
+	}
+
+	return results, nil
+}
+
+// Mask returns a filter that replaces the values at the given paths with
+// placeholder. Paths are jq path expressions or simple JSONPaths; paths that
+// do not exist or hold null are left untouched.
+func Mask(paths []string, placeholder string) (*Filter, error) {
+	if len(paths) == 0 {
+		return Compile(".")
+	}
+
+	exprs := make([]string, 0, len(paths))
+
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if strings.HasPrefix(p, "$") {
+			p = fromJSONPath(p)
+		}
+
+		exprs = append(exprs, "path(("+p+")?)")
+	}
+
+	value, err := json.Marshal(placeholder)
+	if err != nil {
+		return nil, fmt.Errorf("encode placeholder: %w", err)
+	}
+
+	return Compile(fmt.Sprintf(
+		"reduce (%s) as $p (.; if (try getpath($p) catch null) == null then . else setpath($p; %s) end)",
+		strings.Join(exprs, ", "), value,
+	))
+}
+
+var (
+	jsonPathWildcard = regexp.MustCompile(`\[\*\]|\.\*`)
+	jsonPathQuoted   = regexp.MustCompile(`\['([^']*)'\]`)
+)
+
+// fromJSONPath converts a simple JSONPath expression to jq.
+// Supported: $, .field, [n], [*], .* and ['field'].
+func fromJSONPath(path string) string {
+	expr := strings.TrimPrefix(path, "$")
+	expr = jsonPathWildcard.ReplaceAllString(expr, "[]")
+	expr = jsonPathQuoted.ReplaceAllString(expr, `["$1"]`)
+
+	if !strings.HasPrefix(expr, ".") {
+		expr = "." + expr
+	}
+
+	return expr
+}
@@ -0,0 +1,78 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+const testData = `{"users":[{"id":"1","email":"a@example.com","age":12345678901234567890},{"id":"2","email":"b@example.com","age":30}]}`
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		{name: "identity", expr: ".users | length", want: []string{"2"}},
+		{name: "iterate", expr: ".users[].email", want: []string{`"a@example.com"`, `"b@example.com"`}},
+		{name: "select", expr: `.users[] | select(.id == "2") | .email`, want: []string{`"b@example.com"`}},
+		{name: "large numbers", expr: ".users[0].age", want: []string{"12345678901234567890"}},
+		{name: "jsonpath", expr: "$.users[*].id", want: []string{`"1"`, `"2"`}},
+		{name: "jsonpath quoted", expr: "$['users'][1]['email']", want: []string{`"b@example.com"`}},
+		{name: "jsonpath root", expr: "$ | keys", want: []string{`["users"]`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tt.expr, err)
+			}
+
+			results, err := f.Apply([]byte(testData))
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+
+			got := make([]string, len(results))
+			for i, r := range results {
+				got[i] = string(r)
+			}
+
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Compile(".users["); err == nil {
+		t.Error("Compile() expected error for invalid expression")
+	}
+}
+
+func TestMask(t *testing.T) {
+	t.Parallel()
+
+	f, err := Mask([]string{".users[].id", "$.meta.createdAt", ".missing.deep[]"}, "<masked>")
+	if err != nil {
+		t.Fatalf("Mask() error = %v", err)
+	}
+
+	got, err := f.Apply([]byte(`{"users":[{"name":"a","id":"1"},{"id":null}],"meta":{"createdAt":"2024-01-01","v":1}}`))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	want := `{"meta":{"createdAt":"<masked>","v":1},"users":[{"id":"<masked>","name":"a"},{"id":null}]}`
+	if len(got) != 1 || string(got[0]) != want {
+		t.Errorf("Apply() = %s, want %s", got, want)
+	}
+}
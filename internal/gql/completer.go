@@ -56,6 +56,18 @@ func (c *Completer) Complete(d prompt.Document) []prompt.Suggest {
 		return c.completeTypes(prefix)
 	case "call":
 		return c.completeCall(prefix)
+	case "header":
+		return c.completeHeader(prefix)
+	case "cookies":
+		return c.completeCookies(prefix)
+	case "set":
+		return c.completeSet(words, prefix)
+	case "history":
+		return c.completeHistory(prefix)
+	case "export":
+		return c.completeExport(prefix)
+	case "import":
+		return c.completeImport(words, prefix)
 	}
 
 	return nil
@@ -67,6 +79,20 @@ func (c *Completer) commands() []prompt.Suggest {
 		{Text: "show", Description: "Show schema info"},
 		{Text: "desc", Description: "Describe type/field"},
 		{Text: "call", Description: "Call query/mutation"},
+		{Text: "header", Description: "Manage headers"},
+		{Text: "use", Description: "Switch profile/endpoint"},
+		{Text: "cookies", Description: "List or clear cookies"},
+		{Text: "set", Description: "Change settings"},
+		{Text: "history", Description: "Previous responses"},
+		{Text: "trace", Description: "Show resolver trace"},
+		{Text: "export", Description: "Export request"},
+		{Text: "import", Description: "Import curl/.http requests"},
+		{Text: "run", Description: "Run imported request"},
+		{Text: "let", Description: "Capture a variable"},
+		{Text: "watch", Description: "Re-run on an interval"},
+		{Text: "batch", Description: "Send operations in one request"},
+		{Text: "next", Description: "Next page of a connection"},
+		{Text: "prev", Description: "Previous page of a connection"},
 		{Text: "exit", Description: "Exit"},
 	}
 }
@@ -85,6 +111,111 @@ func (c *Completer) completeShow(prefix string) []prompt.Suggest {
 	return prompt.FilterHasPrefix(suggests, prefix, true)
 }
 
+func (c *Completer) completeHeader(prefix string) []prompt.Suggest {
+	suggests := []prompt.Suggest{
+		{Text: "set", Description: "Set a header"},
+		{Text: "unset", Description: "Remove a header"},
+		{Text: "list", Description: "List headers"},
+	}
+	if prefix == "" {
+		return suggests
+	}
+
+	return prompt.FilterHasPrefix(suggests, prefix, true)
+}
+
+func (c *Completer) completeCookies(prefix string) []prompt.Suggest {
+	suggests := []prompt.Suggest{
+		{Text: "list", Description: "List cookies"},
+		{Text: "clear", Description: "Remove all cookies"},
+	}
+	if prefix == "" {
+		return suggests
+	}
+
+	return prompt.FilterHasPrefix(suggests, prefix, true)
+}
+
+func (c *Completer) completeSet(words []string, prefix string) []prompt.Suggest {
+	suggests := []prompt.Suggest{
+		{Text: "output", Description: "Output format"},
+		{Text: "history", Description: "Number of responses to keep"},
+		{Text: "timing", Description: "Show request timing"},
+		{Text: "trace", Description: "Show resolver traces"},
+	}
+
+	// Complete the value once the setting name is complete
+	if len(words) > 2 || (len(words) == 2 && prefix == "") {
+		suggests = nil
+
+		switch words[1] {
+		case "output":
+			suggests = []prompt.Suggest{
+				{Text: "json", Description: "Indented JSON"},
+				{Text: "compact", Description: "Single-line JSON"},
+				{Text: "yaml", Description: "YAML"},
+				{Text: "table", Description: "Table of the first list"},
+				{Text: "raw", Description: "Scalar values only"},
+			}
+		case "timing", "trace":
+			suggests = []prompt.Suggest{
+				{Text: "on", Description: "Show after each response"},
+				{Text: "off", Description: "Hide"},
+			}
+		}
+	}
+
+	if prefix == "" {
+		return suggests
+	}
+
+	return prompt.FilterHasPrefix(suggests, prefix, true)
+}
+
+func (c *Completer) completeHistory(prefix string) []prompt.Suggest {
+	suggests := []prompt.Suggest{
+		{Text: "list", Description: "List responses"},
+		{Text: "show", Description: "Print a response again"},
+		{Text: "save", Description: "Write a response to a file"},
+		{Text: "diff", Description: "Diff two responses"},
+	}
+	if prefix == "" {
+		return suggests
+	}
+
+	return prompt.FilterHasPrefix(suggests, prefix, true)
+}
+
+func (c *Completer) completeExport(prefix string) []prompt.Suggest {
+	suggests := []prompt.Suggest{
+		{Text: "curl", Description: "curl command"},
+		{Text: "httpie", Description: "HTTPie command"},
+		{Text: "go", Description: "Go program"},
+		{Text: "--redact", Description: "Hide secret headers"},
+	}
+	if prefix == "" {
+		return suggests
+	}
+
+	return prompt.FilterHasPrefix(suggests, prefix, true)
+}
+
+func (c *Completer) completeImport(words []string, prefix string) []prompt.Suggest {
+	if len(words) > 2 || (len(words) == 2 && prefix == "") {
+		return nil
+	}
+
+	suggests := []prompt.Suggest{
+		{Text: "curl", Description: "Paste a curl command"},
+		{Text: "http", Description: "Load a .http file"},
+	}
+	if prefix == "" {
+		return suggests
+	}
+
+	return prompt.FilterHasPrefix(suggests, prefix, true)
+}
+
 func (c *Completer) completeTypes(prefix string) []prompt.Suggest {
 	suggests := make([]prompt.Suggest, 0, len(c.schema.Types))
 
@@ -0,0 +1,72 @@
+package gql
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// Operation is a single operation split out of a document.
+type Operation struct {
+	Name string
+	// Query is the operation with only the fragments it uses.
+	Query string
+}
+
+// SplitOperations returns each operation of a document as its own document,
+// in the order they appear.
+func SplitOperations(query string) ([]Operation, error) {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return nil, fmt.Errorf("parse operations: %w", err)
+	}
+
+	ops := make([]Operation, 0, len(doc.Operations))
+
+	for _, op := range doc.Operations {
+		used := make(map[string]bool)
+		collectFragments(doc, op.SelectionSet, used)
+
+		single := &ast.QueryDocument{Operations: ast.OperationList{op}}
+
+		for _, f := range doc.Fragments {
+			if used[f.Name] {
+				single.Fragments = append(single.Fragments, f)
+			}
+		}
+
+		var buf bytes.Buffer
+
+		formatter.NewFormatter(&buf, formatter.WithIndent("  ")).FormatQueryDocument(single)
+
+		ops = append(ops, Operation{Name: op.Name, Query: strings.TrimSpace(buf.String())})
+	}
+
+	return ops, nil
+}
+
+// collectFragments marks the fragments spread in set, directly or through other fragments.
+func collectFragments(doc *ast.QueryDocument, set ast.SelectionSet, used map[string]bool) {
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			collectFragments(doc, s.SelectionSet, used)
+		case *ast.InlineFragment:
+			collectFragments(doc, s.SelectionSet, used)
+		case *ast.FragmentSpread:
+			if used[s.Name] {
+				continue
+			}
+
+			used[s.Name] = true
+
+			if f := doc.Fragments.ForName(s.Name); f != nil {
+				collectFragments(doc, f.SelectionSet, used)
+			}
+		}
+	}
+}
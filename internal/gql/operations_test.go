@@ -0,0 +1,45 @@
+package gql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitOperations(t *testing.T) {
+	t.Parallel()
+
+	ops, err := SplitOperations(`
+query Users { users { ...UserFields } }
+fragment UserFields on User { id ...Names }
+fragment Names on User { name }
+fragment Unused on Post { id }
+mutation Logout { logout }
+`)
+	if err != nil {
+		t.Fatalf("SplitOperations() error = %v", err)
+	}
+
+	if len(ops) != 2 || ops[0].Name != "Users" || ops[1].Name != "Logout" {
+		t.Fatalf("SplitOperations() = %+v", ops)
+	}
+
+	for _, want := range []string{"query Users", "fragment UserFields", "fragment Names"} {
+		if !strings.Contains(ops[0].Query, want) {
+			t.Errorf("Users should contain %q:\n%s", want, ops[0].Query)
+		}
+	}
+
+	for _, unwanted := range []string{"Unused", "Logout"} {
+		if strings.Contains(ops[0].Query, unwanted) {
+			t.Errorf("Users should not contain %q:\n%s", unwanted, ops[0].Query)
+		}
+	}
+
+	if strings.Contains(ops[1].Query, "fragment") {
+		t.Errorf("Logout should have no fragments:\n%s", ops[1].Query)
+	}
+
+	if _, err := SplitOperations("{"); err == nil {
+		t.Error("SplitOperations() expected error for an invalid document")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/vektah/gqlparser/v2"
 	"github.com/vektah/gqlparser/v2/ast"
@@ -48,6 +49,34 @@ func LoadSchemaFromIntrospection(ctx context.Context, c *client.Client) (*ast.Sc
 	return schema, nil
 }
 
+// LoadSchema loads a GraphQL schema from the SDL file at path,
+// or from introspection when path is empty.
+func LoadSchema(ctx context.Context, c *client.Client, path string) (*ast.Schema, error) {
+	if path != "" {
+		return LoadSchemaFromFile(path)
+	}
+
+	return LoadSchemaFromIntrospection(ctx, c)
+}
+
+// LoadSchemaFromFile loads a GraphQL schema from an SDL file.
+func LoadSchemaFromFile(path string) (*ast.Schema, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // schema path from user config
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	schema, err := gqlparser.LoadSchema(&ast.Source{
+		Name:  path,
+		Input: string(data),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	return schema, nil
+}
+
 // introspectionResponse is the response from an introspection query.
 type introspectionResponse struct {
 	Schema introspectionSchema `json:"__schema"` //nolint:tagliatelle // GraphQL spec
@@ -0,0 +1,349 @@
+package importer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// curlValueOptions are the curl options that take a value. Those ParseCurl
+// does not use are skipped together with their value.
+var curlValueOptions = map[string]bool{
+	"-A": true, "-b": true, "-c": true, "-C": true, "-d": true, "-D": true,
+	"-e": true, "-E": true, "-F": true, "-H": true, "-K": true, "-m": true,
+	"-o": true, "-P": true, "-Q": true, "-r": true, "-t": true, "-T": true,
+	"-u": true, "-U": true, "-w": true, "-x": true, "-X": true, "-y": true,
+	"-Y": true, "-z": true,
+	"--abstract-unix-socket": true, "--alt-svc": true, "--aws-sigv4": true,
+	"--cacert": true, "--capath": true, "--cert": true, "--cert-type": true,
+	"--ciphers": true, "--config": true, "--connect-timeout": true,
+	"--connect-to": true, "--continue-at": true, "--cookie": true,
+	"--cookie-jar": true, "--create-file-mode": true, "--crlfile": true,
+	"--curves": true, "--data": true, "--data-ascii": true, "--data-binary": true,
+	"--data-raw": true, "--data-urlencode": true, "--delegation": true,
+	"--dns-interface": true, "--dns-ipv4-addr": true, "--dns-ipv6-addr": true,
+	"--dns-servers": true, "--doh-url": true, "--dump-header": true,
+	"--egd-file": true, "--engine": true, "--etag-compare": true,
+	"--etag-save": true, "--expect100-timeout": true, "--form": true,
+	"--form-string": true, "--happy-eyeballs-timeout-ms": true,
+	"--haproxy-clientip": true, "--header": true, "--hostpubmd5": true,
+	"--hostpubsha256": true, "--hsts": true, "--interface": true,
+	"--ipfs-gateway": true, "--json": true, "--keepalive-time": true,
+	"--key": true, "--key-type": true, "--krb": true, "--libcurl": true,
+	"--limit-rate": true, "--local-port": true, "--login-options": true,
+	"--mail-auth": true, "--mail-from": true, "--mail-rcpt": true,
+	"--max-filesize": true, "--max-redirs": true, "--max-time": true,
+	"--netrc-file": true, "--noproxy": true, "--oauth2-bearer": true,
+	"--output": true, "--output-dir": true, "--pass": true,
+	"--pinnedpubkey": true, "--preproxy": true, "--proto": true,
+	"--proto-default": true, "--proto-redir": true, "--proxy": true,
+	"--proxy-cacert": true, "--proxy-capath": true, "--proxy-cert": true,
+	"--proxy-cert-type": true, "--proxy-ciphers": true, "--proxy-crlfile": true,
+	"--proxy-header": true, "--proxy-key": true, "--proxy-key-type": true,
+	"--proxy-pass": true, "--proxy-pinnedpubkey": true,
+	"--proxy-service-name": true, "--proxy-tls13-ciphers": true,
+	"--proxy-tlsauthtype": true, "--proxy-tlspassword": true,
+	"--proxy-tlsuser": true, "--proxy-user": true, "--proxy1.0": true,
+	"--pubkey": true, "--quote": true, "--random-file": true, "--range": true,
+	"--rate": true, "--referer": true, "--request": true,
+	"--request-target": true, "--resolve": true, "--retry": true,
+	"--retry-delay": true, "--retry-max-time": true, "--sasl-authzid": true,
+	"--service-name": true, "--socks4": true, "--socks4a": true,
+	"--socks5": true, "--socks5-gssapi-service": true, "--socks5-hostname": true,
+	"--speed-limit": true, "--speed-time": true, "--stderr": true,
+	"--telnet-option": true, "--tftp-blksize": true, "--time-cond": true,
+	"--tls-max": true, "--tls13-ciphers": true, "--tlsauthtype": true,
+	"--tlspassword": true, "--tlsuser": true, "--trace": true,
+	"--trace-ascii": true, "--trace-config": true, "--unix-socket": true,
+	"--upload-file": true, "--url": true, "--url-query": true, "--user": true,
+	"--user-agent": true, "--variable": true, "--write-out": true,
+}
+
+// curlCommand is the request read from the options of a curl command.
+type curlCommand struct {
+	req  *Request
+	data []string
+	// get sends the data in the URL, as -G does
+	get bool
+	// form is set when the data is URL encoded by --data-urlencode
+	form bool
+}
+
+// ParseCurl parses a curl command line into a GraphQL request.
+func ParseCurl(command string) (*Request, error) {
+	args, err := splitShell(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) > 0 && args[0] == "curl" {
+		args = args[1:]
+	}
+
+	args = splitShortOptions(args)
+	c := &curlCommand{req: &Request{Headers: make(map[string]string)}}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") {
+			c.req.Endpoint = arg
+
+			continue
+		}
+
+		// --option=value form
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !strings.HasPrefix(arg, "--") {
+			name, hasValue = arg, false
+		}
+
+		if !curlValueOptions[name] {
+			// Options without a value, such as -s, -k or --compressed
+			c.get = c.get || name == "-G" || name == "--get"
+
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("curl: %s needs a value", name)
+			}
+
+			i++
+			value = args[i]
+		}
+
+		if err := c.option(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.finish()
+}
+
+// splitShortOptions splits combined short options such as -sSG into one
+// argument each. The rest of a short option that takes a value, as in -XPOST,
+// is its value. Values of options are kept as they are.
+func splitShortOptions(args []string) []string {
+	out := make([]string, 0, len(args))
+	value := false
+
+	for _, arg := range args {
+		if value || len(arg) <= 2 || arg[0] != '-' || arg[1] == '-' {
+			out = append(out, arg)
+			value = !value && curlValueOptions[arg]
+
+			continue
+		}
+
+		for j := 1; j < len(arg); j++ {
+			name := "-" + arg[j:j+1]
+			out = append(out, name)
+
+			if curlValueOptions[name] {
+				if rest := arg[j+1:]; rest != "" {
+					out = append(out, rest)
+				} else {
+					value = true
+				}
+
+				break
+			}
+		}
+	}
+
+	return out
+}
+
+// option applies an option that takes a value.
+func (c *curlCommand) option(name, value string) error {
+	var err error
+
+	switch name {
+	case "-H", "--header":
+		if k, v, ok := strings.Cut(value, ":"); ok {
+			c.req.setHeader(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+	case "-d", "--data", "--data-ascii", "--data-binary", "--json":
+		if value, err = readDataFile(value); err != nil {
+			return err
+		}
+
+		c.data = append(c.data, value)
+	case "--data-raw":
+		c.data = append(c.data, value)
+	case "--data-urlencode":
+		if value, err = urlEncodeData(value); err != nil {
+			return err
+		}
+
+		c.data = append(c.data, value)
+		c.form = true
+	case "-u", "--user":
+		c.req.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(value))
+	case "-b", "--cookie":
+		c.req.Headers["Cookie"] = value
+	case "-A", "--user-agent":
+		c.req.Headers["User-Agent"] = value
+	case "--url":
+		c.req.Endpoint = value
+	}
+
+	return nil
+}
+
+// urlEncodeData encodes a --data-urlencode value: content, =content,
+// name=content, @file or name@file.
+func urlEncodeData(value string) (string, error) {
+	if name, content, ok := strings.Cut(value, "="); ok {
+		if name == "" {
+			return url.QueryEscape(content), nil
+		}
+
+		return name + "=" + url.QueryEscape(content), nil
+	}
+
+	name, path, ok := strings.Cut(value, "@")
+	if !ok {
+		return url.QueryEscape(value), nil
+	}
+
+	content, err := readDataFile("@" + path)
+	if err != nil {
+		return "", err
+	}
+
+	if name == "" {
+		return url.QueryEscape(content), nil
+	}
+
+	return name + "=" + url.QueryEscape(content), nil
+}
+
+// finish builds the GraphQL request from the URL and the data.
+func (c *curlCommand) finish() (*Request, error) {
+	req := c.req
+	if req.Endpoint == "" {
+		return nil, fmt.Errorf("curl: no URL")
+	}
+
+	data := strings.Join(c.data, "&")
+
+	// -G appends the data to the URL and sends a GET request
+	if c.get && data != "" {
+		sep := "?"
+		if strings.Contains(req.Endpoint, "?") {
+			sep = "&"
+		}
+
+		req.Endpoint += sep + data
+		data = ""
+	}
+
+	var err error
+
+	switch {
+	case data == "":
+		// GET request with the operation in the URL
+		if req.Endpoint, req.Body, err = splitGETRequest(req.Endpoint); err != nil {
+			return nil, fmt.Errorf("curl: %w", err)
+		}
+
+		if req.Body == nil {
+			return nil, fmt.Errorf("curl: no GraphQL request body")
+		}
+	case c.form:
+		if req.Body, err = parseForm(data); err != nil {
+			return nil, fmt.Errorf("curl: %w", err)
+		}
+	default:
+		if req.Body, err = parseBody(data); err != nil {
+			return nil, fmt.Errorf("curl: %w", err)
+		}
+	}
+
+	req.Name = requestName(req.Body, "curl")
+
+	return req, nil
+}
+
+// readDataFile resolves curl's @file syntax for data options.
+func readDataFile(value string) (string, error) {
+	path, ok := strings.CutPrefix(value, "@")
+	if !ok {
+		return value, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path from the user's curl command
+	if err != nil {
+		return "", fmt.Errorf("curl: read %s: %w", path, err)
+	}
+
+	return string(data), nil
+}
+
+// splitShell splits a command line into words using POSIX shell quoting rules.
+// Backslash-newline continuations are removed.
+func splitShell(s string) ([]string, error) {
+	var (
+		words   []string
+		cur     strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, c := range s {
+		switch {
+		case escaped:
+			if c != '\n' {
+				cur.WriteRune(c)
+
+				inWord = true
+			}
+
+			escaped = false
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(c)
+			}
+		case c == '\\' && quote != '\'':
+			escaped = true
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				cur.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+
+				inWord = false
+			}
+		default:
+			cur.WriteRune(c)
+
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("curl: unterminated quote")
+	}
+
+	if inWord {
+		words = append(words, cur.String())
+	}
+
+	return words, nil
+}
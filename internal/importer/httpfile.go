@@ -0,0 +1,256 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+var (
+	// fileVariable matches "@name = value" definitions.
+	fileVariable = regexp.MustCompile(`^@([\w.-]+)\s*=\s*(.*)$`)
+	// nameDirective matches "# @name value" and "// @name value".
+	nameDirective = regexp.MustCompile(`^(?:#|//)\s*@name\s+(\S+)`)
+	// placeholder matches "{{name}}" references to file variables.
+	placeholder = regexp.MustCompile(`\{\{\s*([\w.-]+)\s*\}\}`)
+)
+
+// LoadHTTPFile reads GraphQL requests from a JetBrains or VS Code .http file.
+func LoadHTTPFile(path string) ([]*Request, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	reqs, err := ParseHTTP(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return reqs, nil
+}
+
+// ParseHTTP parses requests separated by "###" lines. Both the JetBrains GRAPHQL
+// method and the VS Code "X-Request-Type: GraphQL" header are supported, as well
+// as plain POST requests with a JSON body and GET requests with a query parameter.
+// "@name = value" file variables are substituted into "{{name}}" placeholders.
+func ParseHTTP(data []byte) ([]*Request, error) {
+	var (
+		blocks [][]string
+		cur    []string
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "###") {
+			blocks = append(blocks, cur)
+			cur = nil
+
+			continue
+		}
+
+		cur = append(cur, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	blocks = append(blocks, cur)
+
+	vars := make(map[string]string)
+
+	var reqs []*Request
+
+	for _, block := range blocks {
+		req, err := parseHTTPBlock(block, vars)
+		if err != nil {
+			return nil, fmt.Errorf("request %d: %w", len(reqs)+1, err)
+		}
+
+		if req == nil {
+			continue
+		}
+
+		if req.Name == "" {
+			req.Name = requestName(req.Body, fmt.Sprintf("request%d", len(reqs)+1))
+		}
+
+		reqs = append(reqs, req)
+	}
+
+	return reqs, nil
+}
+
+// parseHTTPBlock parses a single request. It returns nil for blocks that only
+// hold comments or variable definitions.
+func parseHTTPBlock(lines []string, vars map[string]string) (*Request, error) {
+	req := &Request{Headers: make(map[string]string)}
+
+	i := 0
+
+	var method, target string
+
+	// Comments, variables and directives before the request line
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+
+		switch {
+		case line == "":
+		case nameDirective.MatchString(line):
+			req.Name = nameDirective.FindStringSubmatch(line)[1]
+		case strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//"):
+		case fileVariable.MatchString(line):
+			m := fileVariable.FindStringSubmatch(line)
+			vars[m[1]] = expandVars(strings.TrimSpace(m[2]), vars)
+		default:
+			method, target = parseRequestLine(expandVars(line, vars))
+		}
+
+		if target != "" {
+			i++
+
+			break
+		}
+	}
+
+	if target == "" {
+		return nil, nil //nolint:nilnil // no request in this block
+	}
+
+	req.Endpoint = target
+
+	graphql := method == "GRAPHQL"
+
+	// Headers up to the first blank line
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			i++
+
+			break
+		}
+
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(expandVars(line, vars), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header: %s", line)
+		}
+
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if strings.EqualFold(name, "X-Request-Type") && strings.EqualFold(value, "GraphQL") {
+			graphql = true
+
+			continue
+		}
+
+		req.setHeader(name, value)
+	}
+
+	body := expandVars(requestBody(lines[i:]), vars)
+
+	var err error
+
+	switch {
+	case graphql:
+		req.Body, err = parseGraphQLBody(body)
+	case strings.TrimSpace(body) == "":
+		req.Endpoint, req.Body, err = splitGETRequest(req.Endpoint)
+		if err == nil && req.Body == nil {
+			err = fmt.Errorf("%s %s is not a GraphQL request", method, target)
+		}
+	default:
+		req.Body, err = parseBody(body)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// parseRequestLine splits "METHOD URL [HTTP/version]". A bare URL is a GET request.
+func parseRequestLine(line string) (string, string) {
+	fields := strings.Fields(line)
+	if len(fields) > 1 && strings.HasPrefix(fields[len(fields)-1], "HTTP/") {
+		fields = fields[:len(fields)-1]
+	}
+
+	switch len(fields) {
+	case 0:
+		return "", ""
+	case 1:
+		return "GET", fields[0]
+	default:
+		return strings.ToUpper(fields[0]), fields[1]
+	}
+}
+
+// requestBody joins the body lines, stopping at response handlers and redirections.
+func requestBody(lines []string) string {
+	var body []string
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "> ") || strings.HasPrefix(trimmed, ">>") || strings.HasPrefix(trimmed, "<> ") {
+			break
+		}
+
+		body = append(body, line)
+	}
+
+	return strings.TrimSpace(strings.Join(body, "\n"))
+}
+
+// parseGraphQLBody parses a raw GraphQL document optionally followed by a blank
+// line and a JSON object of variables.
+func parseGraphQLBody(body string) (*client.Request, error) {
+	if body == "" {
+		return nil, fmt.Errorf("no GraphQL query")
+	}
+
+	req := &client.Request{Query: body}
+
+	idx := strings.LastIndex(body, "\n\n")
+	if idx < 0 {
+		return req, nil
+	}
+
+	tail := strings.TrimSpace(body[idx:])
+	if !strings.HasPrefix(tail, "{") || !json.Valid([]byte(tail)) {
+		return req, nil
+	}
+
+	if err := json.Unmarshal([]byte(tail), &req.Variables); err != nil {
+		return nil, fmt.Errorf("decode variables: %w", err)
+	}
+
+	req.Query = strings.TrimSpace(body[:idx])
+
+	return req, nil
+}
+
+// expandVars replaces "{{name}}" placeholders with file variables.
+// Unknown placeholders, such as environment variables, are left untouched.
+func expandVars(s string, vars map[string]string) string {
+	return placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := vars[placeholder.FindStringSubmatch(m)[1]]; ok {
+			return v
+		}
+
+		return m
+	})
+}
@@ -0,0 +1,119 @@
+// Package importer reads GraphQL requests from curl command lines and .http files.
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+// Request is an imported GraphQL request.
+type Request struct {
+	// Name identifies the request, from "# @name", the operation name or its position.
+	Name     string
+	Endpoint string
+	Headers  map[string]string
+	Body     *client.Request
+}
+
+// ignoredHeaders are set by the client itself and are not imported.
+var ignoredHeaders = []string{"Content-Type", "Content-Length", "Host"}
+
+func (r *Request) setHeader(name, value string) {
+	for _, h := range ignoredHeaders {
+		if strings.EqualFold(name, h) {
+			return
+		}
+	}
+
+	r.Headers[name] = value
+}
+
+// parseBody decodes a GraphQL request body. JSON bodies must contain a query;
+// anything else is treated as a raw GraphQL document (application/graphql).
+func parseBody(body string) (*client.Request, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("no request body")
+	}
+
+	if !strings.HasPrefix(body, "{") || !json.Valid([]byte(body)) {
+		return &client.Request{Query: body}, nil
+	}
+
+	var req client.Request
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		return nil, fmt.Errorf("decode body: %w", err)
+	}
+
+	if req.Query == "" {
+		return nil, fmt.Errorf("body has no query")
+	}
+
+	return &req, nil
+}
+
+// splitGETRequest extracts a GraphQL request from the query, variables and
+// operationName URL parameters, returning the URL without them.
+func splitGETRequest(rawURL string) (string, *client.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("parse url: %w", err)
+	}
+
+	params := u.Query()
+	if params.Get("query") == "" {
+		return rawURL, nil, nil
+	}
+
+	req, err := paramsRequest(params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, key := range []string{"query", "variables", "operationName", "extensions"} {
+		params.Del(key)
+	}
+
+	u.RawQuery = params.Encode()
+
+	return u.String(), req, nil
+}
+
+// parseForm decodes a GraphQL request from a URL encoded form body.
+func parseForm(body string) (*client.Request, error) {
+	params, err := url.ParseQuery(body)
+	if err != nil {
+		return nil, fmt.Errorf("decode form body: %w", err)
+	}
+
+	if params.Get("query") == "" {
+		return nil, fmt.Errorf("form body has no query")
+	}
+
+	return paramsRequest(params)
+}
+
+// paramsRequest decodes the query, variables and operationName parameters.
+func paramsRequest(params url.Values) (*client.Request, error) {
+	req := &client.Request{Query: params.Get("query"), OperationName: params.Get("operationName")}
+
+	if v := params.Get("variables"); v != "" {
+		if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+			return nil, fmt.Errorf("decode variables: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func requestName(body *client.Request, fallback string) string {
+	if body.OperationName != "" {
+		return body.OperationName
+	}
+
+	return fallback
+}
@@ -0,0 +1,194 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCurl(t *testing.T) {
+	t.Parallel()
+
+	cmd := `curl -X POST 'https://api.example.com/graphql' \
+  -H 'Content-Type: application/json' \
+  -H "Authorization: Bearer secret" \
+  --compressed -s \
+  --data-raw '{"query":"query GetUser($id: ID!) { user(id: $id) { name } }","variables":{"id":"1"},"operationName":"GetUser"}'`
+
+	req, err := ParseCurl(cmd)
+	if err != nil {
+		t.Fatalf("ParseCurl() error = %v", err)
+	}
+
+	if req.Endpoint != "https://api.example.com/graphql" {
+		t.Errorf("Endpoint = %q", req.Endpoint)
+	}
+
+	if want := map[string]string{"Authorization": "Bearer secret"}; !reflect.DeepEqual(req.Headers, want) {
+		t.Errorf("Headers = %v, want %v", req.Headers, want)
+	}
+
+	if req.Name != "GetUser" || req.Body.Variables["id"] != "1" {
+		t.Errorf("Name = %q, Variables = %v", req.Name, req.Body.Variables)
+	}
+}
+
+func TestParseCurl_GET(t *testing.T) {
+	t.Parallel()
+
+	req, err := ParseCurl(`curl --url='https://api.example.com/graphql?query=%7B%20me%20%7B%20id%20%7D%20%7D&v=2' -u user:pass`)
+	if err != nil {
+		t.Fatalf("ParseCurl() error = %v", err)
+	}
+
+	if req.Endpoint != "https://api.example.com/graphql?v=2" || req.Body.Query != "{ me { id } }" {
+		t.Errorf("Endpoint = %q, Query = %q", req.Endpoint, req.Body.Query)
+	}
+
+	if got := req.Headers["Authorization"]; got != "Basic dXNlcjpwYXNz" {
+		t.Errorf("Authorization = %q", got)
+	}
+}
+
+func TestParseCurl_Options(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		cmd          string
+		wantEndpoint string
+		wantQuery    string
+		wantVars     map[string]any
+	}{
+		{
+			name:         "data-urlencode with -G",
+			cmd:          `curl -G --data-urlencode 'query={ user(id: $id) { name } }' --data-urlencode 'variables={"id":"1"}' https://api.example.com/graphql`,
+			wantEndpoint: "https://api.example.com/graphql",
+			wantQuery:    "{ user(id: $id) { name } }",
+			wantVars:     map[string]any{"id": "1"},
+		},
+		{
+			name:         "-G keeps the other URL parameters",
+			cmd:          `curl -sG 'https://api.example.com/graphql?v=2' -d 'query={ me { id } }'`,
+			wantEndpoint: "https://api.example.com/graphql?v=2",
+			wantQuery:    "{ me { id } }",
+		},
+		{
+			name:         "form body",
+			cmd:          `curl https://api.example.com/graphql --data-urlencode 'query=query Me { me { id } }' -d operationName=Me`,
+			wantEndpoint: "https://api.example.com/graphql",
+			wantQuery:    "query Me { me { id } }",
+		},
+		{
+			name:         "unhandled options with values",
+			cmd:          `curl --retry-delay 2 --proxy-user u:p --limit-rate 1M -sSLXPOST https://api.example.com/graphql -d '{"query":"{ a }"}'`,
+			wantEndpoint: "https://api.example.com/graphql",
+			wantQuery:    "{ a }",
+		},
+		{
+			name:         "value starting with a dash",
+			cmd:          `curl https://api.example.com/graphql -A -agent- --data-raw '{"query":"{ b }"}'`,
+			wantEndpoint: "https://api.example.com/graphql",
+			wantQuery:    "{ b }",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := ParseCurl(tt.cmd)
+			if err != nil {
+				t.Fatalf("ParseCurl() error = %v", err)
+			}
+
+			if req.Endpoint != tt.wantEndpoint || req.Body.Query != tt.wantQuery {
+				t.Errorf("Endpoint = %q, Query = %q; want %q, %q", req.Endpoint, req.Body.Query, tt.wantEndpoint, tt.wantQuery)
+			}
+
+			if tt.wantVars != nil && !reflect.DeepEqual(req.Body.Variables, tt.wantVars) {
+				t.Errorf("Variables = %v, want %v", req.Body.Variables, tt.wantVars)
+			}
+		})
+	}
+}
+
+func TestParseCurl_Errors(t *testing.T) {
+	t.Parallel()
+
+	for _, cmd := range []string{
+		`curl -d '{"query":"{ a }"}'`,
+		`curl https://api.example.com/graphql`,
+		`curl 'https://api.example.com`,
+		`curl https://api.example.com/graphql -H`,
+	} {
+		if _, err := ParseCurl(cmd); err == nil {
+			t.Errorf("ParseCurl(%q) expected error", cmd)
+		}
+	}
+}
+
+func TestParseHTTP(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`@host = https://api.example.com
+@token = secret
+
+### Plain JSON body
+POST {{host}}/graphql HTTP/1.1
+Content-Type: application/json
+Authorization: Bearer {{token}}
+
+{"query": "{ users { id } }"}
+
+###
+# @name getUser
+GRAPHQL {{host}}/graphql
+X-Env: {{env}}
+
+query GetUser($id: ID!) {
+  user(id: $id) { name }
+}
+
+{"id": "1"}
+
+> {% client.global.set("name", response.body.data.user.name) %}
+
+### VS Code
+POST {{host}}/graphql
+X-Request-Type: GraphQL
+
+query Viewer { viewer { login } }
+`)
+
+	reqs, err := ParseHTTP(data)
+	if err != nil {
+		t.Fatalf("ParseHTTP() error = %v", err)
+	}
+
+	if len(reqs) != 3 {
+		t.Fatalf("got %d requests, want 3", len(reqs))
+	}
+
+	if reqs[0].Name != "request1" || reqs[0].Endpoint != "https://api.example.com/graphql" ||
+		reqs[0].Headers["Authorization"] != "Bearer secret" || reqs[0].Body.Query != "{ users { id } }" {
+		t.Errorf("reqs[0] = %+v", reqs[0])
+	}
+
+	if reqs[1].Name != "getUser" || reqs[1].Headers["X-Env"] != "{{env}}" ||
+		reqs[1].Body.Query != "query GetUser($id: ID!) {\n  user(id: $id) { name }\n}" ||
+		reqs[1].Body.Variables["id"] != "1" {
+		t.Errorf("reqs[1] = %+v, body %+v", reqs[1], reqs[1].Body)
+	}
+
+	if reqs[2].Name != "request3" || len(reqs[2].Headers) != 0 || reqs[2].Body.Query != "query Viewer { viewer { login } }" {
+		t.Errorf("reqs[2] = %+v", reqs[2])
+	}
+}
+
+func TestParseHTTP_NotGraphQL(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ParseHTTP([]byte("GET https://example.com/health\n")); err == nil {
+		t.Error("ParseHTTP() expected error")
+	}
+}
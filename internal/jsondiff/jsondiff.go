@@ -0,0 +1,182 @@
+// Package jsondiff computes structural differences between JSON documents.
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Kind is the kind of a change.
+type Kind string
+
+// Change kinds.
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// Change is a single difference between two documents.
+type Change struct {
+	// Path is a jq-style path such as .users[0].email.
+	Path string
+	Kind Kind
+	Old  any
+	New  any
+}
+
+// Compare decodes two JSON documents and returns their differences.
+func Compare(a, b []byte) ([]Change, error) {
+	va, err := decode(a)
+	if err != nil {
+		return nil, err
+	}
+
+	vb, err := decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return Diff(va, vb), nil
+}
+
+// Diff returns the differences between two decoded JSON values.
+// Arrays are compared element by element; object keys are visited in sorted order.
+func Diff(a, b any) []Change {
+	var changes []Change
+
+	diff("", a, b, &changes)
+
+	return changes
+}
+
+func decode(data []byte) (any, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil //nolint:nilnil // an empty document compares as null
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	return v, nil
+}
+
+func diff(path string, a, b any, changes *[]Change) {
+	switch va := a.(type) {
+	case map[string]any:
+		if vb, ok := b.(map[string]any); ok {
+			diffObjects(path, va, vb, changes)
+
+			return
+		}
+	case []any:
+		if vb, ok := b.([]any); ok {
+			diffArrays(path, va, vb, changes)
+
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, Change{Path: displayPath(path), Kind: Changed, Old: a, New: b})
+	}
+}
+
+func diffObjects(path string, a, b map[string]any, changes *[]Change) {
+	keys := make([]string, 0, len(a)+len(b))
+
+	for k := range a {
+		keys = append(keys, k)
+	}
+
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p := path + keyPath(k)
+		va, inA := a[k]
+		vb, inB := b[k]
+
+		switch {
+		case !inA:
+			*changes = append(*changes, Change{Path: p, Kind: Added, New: vb})
+		case !inB:
+			*changes = append(*changes, Change{Path: p, Kind: Removed, Old: va})
+		default:
+			diff(p, va, vb, changes)
+		}
+	}
+}
+
+func diffArrays(path string, a, b []any, changes *[]Change) {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		p := path + "[" + strconv.Itoa(i) + "]"
+
+		switch {
+		case i >= len(a):
+			*changes = append(*changes, Change{Path: p, Kind: Added, New: b[i]})
+		case i >= len(b):
+			*changes = append(*changes, Change{Path: p, Kind: Removed, Old: a[i]})
+		default:
+			diff(p, a[i], b[i], changes)
+		}
+	}
+}
+
+// keyPath formats an object key as a path segment, quoting it when needed.
+func keyPath(k string) string {
+	if isIdentifier(k) {
+		return "." + k
+	}
+
+	return "[" + strconv.Quote(k) + "]"
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, c := range s {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "."
+	}
+
+	return path
+}
+
+// Format renders a value compactly for display.
+func Format(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,79 @@
+package jsondiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a, b string
+		want []string
+	}{
+		{
+			name: "equal",
+			a:    `{"a":1,"b":[1,2]}`,
+			b:    `{"b":[1,2],"a":1}`,
+			want: nil,
+		},
+		{
+			name: "changed scalar",
+			a:    `{"user":{"name":"alice"}}`,
+			b:    `{"user":{"name":"bob"}}`,
+			want: []string{`changed .user.name "alice" -> "bob"`},
+		},
+		{
+			name: "added and removed keys",
+			a:    `{"a":1,"b":2}`,
+			b:    `{"b":2,"c":3}`,
+			want: []string{`removed .a 1 -> null`, `added .c null -> 3`},
+		},
+		{
+			name: "array length",
+			a:    `{"ids":[1,2,3]}`,
+			b:    `{"ids":[1,5]}`,
+			want: []string{`changed .ids[1] 2 -> 5`, `removed .ids[2] 3 -> null`},
+		},
+		{
+			name: "type change",
+			a:    `{"a":{"b":1}}`,
+			b:    `{"a":[1]}`,
+			want: []string{`changed .a {"b":1} -> [1]`},
+		},
+		{
+			name: "quoted key",
+			a:    `{"my-key":1}`,
+			b:    `{"my-key":2}`,
+			want: []string{`changed ["my-key"] 1 -> 2`},
+		},
+		{
+			name: "root",
+			a:    `1`,
+			b:    `2`,
+			want: []string{`changed . 1 -> 2`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			changes, err := Compare([]byte(tt.a), []byte(tt.b))
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+
+			var got []string
+			for _, c := range changes {
+				got = append(got, string(c.Kind)+" "+c.Path+" "+Format(c.Old)+" -> "+Format(c.New))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
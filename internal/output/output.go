@@ -0,0 +1,203 @@
+// Package output renders GraphQL response data in various formats.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format.
+type Format string
+
+// Supported output formats.
+const (
+	JSON    Format = "json"
+	Compact Format = "compact"
+	YAML    Format = "yaml"
+	Table   Format = "table"
+	Raw     Format = "raw"
+)
+
+// Formats lists all supported formats.
+var Formats = []Format{JSON, Compact, YAML, Table, Raw}
+
+// ParseFormat parses a format name.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown output format: %s (use: json, compact, yaml, table, raw)", s)
+}
+
+// Write renders the JSON document data to w in the given format.
+// Object keys keep the order in which they appear in data.
+func Write(w io.Writer, format Format, data []byte) error {
+	switch format {
+	case JSON:
+		return writeJSON(w, data, true)
+	case Compact:
+		return writeJSON(w, data, false)
+	case YAML, Table, Raw:
+		v, err := decode(data)
+		if err != nil {
+			return err
+		}
+
+		return writeValue(w, format, v)
+	default:
+		return fmt.Errorf("unknown output format: %s", format)
+	}
+}
+
+func writeValue(w io.Writer, format Format, v any) error {
+	switch format {
+	case YAML:
+		return writeYAML(w, v)
+	case Table:
+		return writeTable(w, v)
+	case Raw:
+		return writeRaw(w, v)
+	case JSON, Compact:
+	}
+
+	return fmt.Errorf("unknown output format: %s", format)
+}
+
+func writeJSON(w io.Writer, data []byte, indent bool) error {
+	var buf bytes.Buffer
+
+	var err error
+	if indent {
+		err = json.Indent(&buf, data, "", "  ")
+	} else {
+		err = json.Compact(&buf, data)
+	}
+
+	if err != nil {
+		return fmt.Errorf("format json: %w", err)
+	}
+
+	buf.WriteByte('\n')
+
+	if _, err := buf.WriteTo(w); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+func writeYAML(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(yamlNode(v)); err != nil {
+		return fmt.Errorf("encode yaml: %w", err)
+	}
+
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("encode yaml: %w", err)
+	}
+
+	return nil
+}
+
+func yamlNode(v any) *yaml.Node {
+	switch val := v.(type) {
+	case object:
+		n := &yaml.Node{Kind: yaml.MappingNode}
+		if len(val) == 0 {
+			n.Style = yaml.FlowStyle
+		}
+
+		for _, m := range val {
+			n.Content = append(n.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: m.key},
+				yamlNode(m.value),
+			)
+		}
+
+		return n
+	case []any:
+		n := &yaml.Node{Kind: yaml.SequenceNode}
+		if len(val) == 0 {
+			n.Style = yaml.FlowStyle
+		}
+
+		for _, e := range val {
+			n.Content = append(n.Content, yamlNode(e))
+		}
+
+		return n
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: val}
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(val.String(), ".eE") {
+			tag = "!!float"
+		}
+
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: val.String()}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(val)}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+}
+
+// writeRaw prints every scalar leaf on its own line, without quotes.
+func writeRaw(w io.Writer, v any) error {
+	var sb strings.Builder
+
+	walkLeaves(v, func(leaf any) {
+		sb.WriteString(scalarString(leaf))
+		sb.WriteByte('\n')
+	})
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+func walkLeaves(v any, fn func(any)) {
+	switch val := v.(type) {
+	case object:
+		for _, m := range val {
+			walkLeaves(m.value, fn)
+		}
+	case []any:
+		for _, e := range val {
+			walkLeaves(e, fn)
+		}
+	default:
+		fn(val)
+	}
+}
+
+// scalarString formats a scalar value without quotes. Composite values are
+// rendered as compact JSON.
+func scalarString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return fmt.Sprint(val)
+	default:
+		b, _ := json.Marshal(val)
+
+		return string(b)
+	}
+}
@@ -0,0 +1,85 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+const testData = `{"users":[{"id":"1","email":"a@example.com","profile":{"age":30}},{"id":"2","email":"b@example.com","profile":{"age":null}}]}`
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format Format
+		data   string
+		want   string
+	}{
+		{
+			name:   "json keeps key order",
+			format: JSON,
+			data:   `{"b":1,"a":[true]}`,
+			want:   "{\n  \"b\": 1,\n  \"a\": [\n    true\n  ]\n}\n",
+		},
+		{
+			name:   "compact",
+			format: Compact,
+			data:   "{\n  \"b\": 1,\n  \"a\": \"x\"\n}",
+			want:   `{"b":1,"a":"x"}` + "\n",
+		},
+		{
+			name:   "yaml",
+			format: YAML,
+			data:   `{"user":{"id":"1","name":"true","tags":[],"score":1.5,"bio":null}}`,
+			want:   "user:\n  id: \"1\"\n  name: \"true\"\n  tags: []\n  score: 1.5\n  bio: null\n",
+		},
+		{
+			name:   "raw",
+			format: Raw,
+			data:   `{"users":[{"email":"a@example.com"},{"email":"b@example.com"}],"count":2}`,
+			want:   "a@example.com\nb@example.com\n2\n",
+		},
+		{
+			name:   "table",
+			format: Table,
+			data:   testData,
+			want: "id  email          profile.age\n" +
+				"1   a@example.com  30\n" +
+				"2   b@example.com  \n",
+		},
+		{
+			name:   "table without list",
+			format: Table,
+			data:   `{"me":{"id":"1","name":"alice"}}`,
+			want:   "me.id  me.name\n1      alice\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.format, []byte(tt.data)); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Write() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	if got, err := ParseFormat("YAML"); err != nil || got != YAML {
+		t.Errorf("ParseFormat(YAML) = %q, %v", got, err)
+	}
+
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) expected error")
+	}
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// writeTable renders the first list found in v as a table.
+// Nested objects are flattened into dotted column names.
+func writeTable(w io.Writer, v any) error {
+	rows, ok := firstList(v)
+	if !ok {
+		// No list: render the value as a single row
+		rows = []any{v}
+	}
+
+	var columns []string
+
+	seen := make(map[string]bool)
+	cells := make([]map[string]string, 0, len(rows))
+
+	for _, row := range rows {
+		cell := make(map[string]string)
+		flatten("", row, cell, func(col string) {
+			if !seen[col] {
+				seen[col] = true
+				columns = append(columns, col)
+			}
+		})
+
+		cells = append(cells, cell)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+
+	for _, cell := range cells {
+		values := make([]string, len(columns))
+		for i, col := range columns {
+			values[i] = cell[col]
+		}
+
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+// firstList returns the first array in v, searching depth-first in document order.
+func firstList(v any) ([]any, bool) {
+	switch val := v.(type) {
+	case []any:
+		return val, true
+	case object:
+		for _, m := range val {
+			if list, ok := firstList(m.value); ok {
+				return list, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// flatten stores the scalar values of v in cell, keyed by dotted path.
+// Arrays are kept as compact JSON in a single cell.
+func flatten(prefix string, v any, cell map[string]string, addColumn func(string)) {
+	obj, ok := v.(object)
+	if !ok {
+		col := prefix
+		if col == "" {
+			col = "value"
+		}
+
+		addColumn(col)
+
+		if v != nil {
+			cell[col] = scalarString(v)
+		}
+
+		return
+	}
+
+	for _, m := range obj {
+		key := m.key
+		if prefix != "" {
+			key = prefix + "." + m.key
+		}
+
+		flatten(key, m.value, cell, addColumn)
+	}
+}
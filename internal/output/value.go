@@ -0,0 +1,120 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// object is a JSON object that keeps its keys in document order.
+type object []member
+
+// member is a key/value pair of an object.
+type member struct {
+	key   string
+	value any
+}
+
+// MarshalJSON encodes the object with its keys in order.
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, fmt.Errorf("marshal key: %w", err)
+		}
+
+		val, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s: %w", m.key, err)
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// decode parses a JSON document into object, []any, string, json.Number, bool or nil values.
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	v, err := decodeValue(dec)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decode: unexpected data after value")
+	}
+
+	return v, nil
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by decode
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := object{}
+
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err //nolint:wrapcheck // wrapped by decode
+			}
+
+			key, _ := keyTok.(string)
+
+			val, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			obj = append(obj, member{key: key, value: val})
+		}
+
+		_, err = dec.Token()
+
+		return obj, err //nolint:wrapcheck // wrapped by decode
+	case '[':
+		arr := []any{}
+
+		for dec.More() {
+			val, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, val)
+		}
+
+		_, err = dec.Token()
+
+		return arr, err //nolint:wrapcheck // wrapped by decode
+	default:
+		return nil, fmt.Errorf("unexpected delimiter: %s", delim)
+	}
+}
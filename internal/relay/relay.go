@@ -0,0 +1,657 @@
+// Package relay pages through Relay cursor connections by re-running an
+// operation with the cursor of a connection field moved.
+package relay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+var (
+	// ErrNoNextPage is returned by Next when the connection has no further pages.
+	ErrNoNextPage = errors.New("no next page")
+	// ErrNoPrevPage is returned by Prev on the first page of the connection, or
+	// on the first page visited when it cannot be paged backward.
+	ErrNoPrevPage = errors.New("no previous page")
+	// ErrNoPageInfo is returned when the response lacks the pageInfo of the connection.
+	ErrNoPageInfo = errors.New("response has no pageInfo for the connection")
+)
+
+// IsConnection reports whether field is a Relay connection: it takes first/after
+// or last/before arguments and returns a type with edges and pageInfo fields.
+func IsConnection(schema *ast.Schema, field *ast.FieldDefinition) bool {
+	args := field.Arguments
+	forward := args.ForName("first") != nil && args.ForName("after") != nil
+	backward := args.ForName("last") != nil && args.ForName("before") != nil
+
+	if !forward && !backward {
+		return false
+	}
+
+	def := schema.Types[field.Type.Name()]
+
+	return def != nil && def.Fields.ForName("edges") != nil && def.Fields.ForName("pageInfo") != nil
+}
+
+// connection is a connection field selected by the operation, with the
+// response keys used to read it.
+type connection struct {
+	field *ast.Field
+	// path is the response keys from data to the connection object
+	path []string
+
+	pageInfo    string
+	endCursor   string
+	hasNextPage string
+
+	// backward is set when the connection also takes last and before, and its
+	// pageInfo has startCursor and hasPreviousPage, which are selected once
+	// the connection is paged backward
+	backward        bool
+	startCursor     string
+	hasPreviousPage string
+}
+
+// Pager re-runs an operation to move forward and back through the pages of one
+// of its connections. Cursors are passed through the after and before
+// arguments, or the variables they reference. Moving back returns to the pages
+// already visited, then continues with last and before when the connection
+// supports backward pagination.
+type Pager struct {
+	doc           *ast.QueryDocument
+	conn          *connection
+	variables     map[string]any
+	operationName string
+
+	// query is the original text, sent as is until the document is modified,
+	// so that the operation still matches a trusted documents manifest
+	query    string
+	modified bool
+
+	// size is the page size given to first, or to last when paging backward
+	size *ast.Value
+	// pos is the current page; visited holds the pages moved forward from
+	pos     position
+	visited []position
+	// offset is the current page from the page the pager started on, and
+	// lowest the earliest page reached
+	offset int
+	lowest int
+}
+
+// position is a page: the cursor it is fetched from, and whether it is
+// fetched backward with last and before.
+type position struct {
+	cursor   *string
+	backward bool
+}
+
+// NewPager prepares req for paging through the connection at name, a dotted
+// response path such as "viewer.repositories". An empty name selects the first
+// connection of the operation. The pageInfo fields needed for paging forward
+// are added to the selection when missing, and those for paging backward the
+// first time Prev needs them.
+func NewPager(schema *ast.Schema, req *client.Request, name string) (*Pager, error) {
+	doc, errs := gqlparser.LoadQuery(schema, req.Query)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("parse operation: %w", errs)
+	}
+
+	op, err := operation(doc, req.OperationName)
+	if err != nil {
+		return nil, err
+	}
+
+	var conns []*connection
+
+	findConnections(schema, op.SelectionSet, nil, &conns)
+
+	conn, err := selectConnection(conns, name)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pager{
+		doc:           doc,
+		conn:          conn,
+		variables:     maps.Clone(req.Variables),
+		operationName: req.OperationName,
+		query:         req.Query,
+	}
+	if p.variables == nil {
+		p.variables = make(map[string]any)
+	}
+
+	p.start()
+
+	pageInfo := p.ensureField(&conn.field.SelectionSet, "pageInfo")
+	conn.pageInfo = pageInfo.Alias
+	conn.endCursor = p.ensureField(&pageInfo.SelectionSet, "endCursor").Alias
+	conn.hasNextPage = p.ensureField(&pageInfo.SelectionSet, "hasNextPage").Alias
+
+	return p, nil
+}
+
+// start sets the page the operation fetches: backward when it gives last but
+// not first.
+func (p *Pager) start() {
+	args := p.conn.field.Arguments
+
+	if first := args.ForName("first"); first != nil || args.ForName("last") == nil {
+		if first != nil {
+			p.size = p.literal(first.Value)
+		}
+
+		p.pos = position{cursor: p.argument("after")}
+
+		return
+	}
+
+	p.size = p.literal(args.ForName("last").Value)
+	p.pos = position{cursor: p.argument("before"), backward: true}
+}
+
+// literal returns the page size given by v as an integer literal, since the
+// variable it references may be cleared when the direction changes.
+func (p *Pager) literal(v *ast.Value) *ast.Value {
+	size, err := v.Value(p.variables)
+	if err != nil {
+		return nil
+	}
+
+	switch n := size.(type) {
+	case int64:
+		return &ast.Value{Kind: ast.IntValue, Raw: strconv.FormatInt(n, 10)}
+	case int:
+		return &ast.Value{Kind: ast.IntValue, Raw: strconv.Itoa(n)}
+	case float64:
+		return &ast.Value{Kind: ast.IntValue, Raw: strconv.FormatFloat(n, 'f', -1, 64)}
+	case json.Number:
+		return &ast.Value{Kind: ast.IntValue, Raw: n.String()}
+	default:
+		return nil
+	}
+}
+
+// Connections returns the response paths of the connections selected by query
+// that can be paged.
+func Connections(schema *ast.Schema, query string) []string {
+	doc, errs := gqlparser.LoadQuery(schema, query)
+	if len(errs) > 0 {
+		return nil
+	}
+
+	var conns []*connection
+
+	for _, op := range doc.Operations {
+		if op.Operation == ast.Query {
+			findConnections(schema, op.SelectionSet, nil, &conns)
+		}
+	}
+
+	paths := make([]string, 0, len(conns))
+	for _, c := range conns {
+		paths = append(paths, strings.Join(c.path, "."))
+	}
+
+	return paths
+}
+
+func operation(doc *ast.QueryDocument, name string) (*ast.OperationDefinition, error) {
+	var op *ast.OperationDefinition
+
+	switch {
+	case name != "":
+		if op = doc.Operations.ForName(name); op == nil {
+			return nil, fmt.Errorf("operation %q not found", name)
+		}
+	case len(doc.Operations) == 1:
+		op = doc.Operations[0]
+	default:
+		return nil, fmt.Errorf("the document has %d operations: set the operation name", len(doc.Operations))
+	}
+
+	if op.Operation != ast.Query {
+		return nil, fmt.Errorf("only queries can be paged, not %s", op.Operation)
+	}
+
+	return op, nil
+}
+
+// findConnections collects the pageable connections in set. It does not descend
+// into lists, where a path would match many connections, nor into connections.
+func findConnections(schema *ast.Schema, set ast.SelectionSet, path []string, conns *[]*connection) {
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if s.Definition == nil {
+				continue
+			}
+
+			p := append(append([]string(nil), path...), s.Alias)
+
+			switch {
+			case IsConnection(schema, s.Definition) && s.Definition.Arguments.ForName("after") != nil:
+				*conns = append(*conns, &connection{field: s, path: p, backward: pagesBackward(schema, s.Definition)})
+			case s.Definition.Type.Elem == nil:
+				findConnections(schema, s.SelectionSet, p, conns)
+			}
+		case *ast.InlineFragment:
+			findConnections(schema, s.SelectionSet, path, conns)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				findConnections(schema, s.Definition.SelectionSet, path, conns)
+			}
+		}
+	}
+}
+
+// pagesBackward reports whether the connection field takes last and before,
+// and its pageInfo has the fields needed to move back.
+func pagesBackward(schema *ast.Schema, field *ast.FieldDefinition) bool {
+	if field.Arguments.ForName("last") == nil || field.Arguments.ForName("before") == nil {
+		return false
+	}
+
+	pageInfo := schema.Types[field.Type.Name()].Fields.ForName("pageInfo")
+	if pageInfo == nil {
+		return false
+	}
+
+	def := schema.Types[pageInfo.Type.Name()]
+
+	return def != nil && def.Fields.ForName("startCursor") != nil && def.Fields.ForName("hasPreviousPage") != nil
+}
+
+func selectConnection(conns []*connection, name string) (*connection, error) {
+	if len(conns) == 0 {
+		return nil, fmt.Errorf("the operation selects no connection with an after argument")
+	}
+
+	if name == "" {
+		return conns[0], nil
+	}
+
+	paths := make([]string, 0, len(conns))
+
+	for _, c := range conns {
+		path := strings.Join(c.path, ".")
+		if path == name {
+			return c, nil
+		}
+
+		paths = append(paths, path)
+	}
+
+	return nil, fmt.Errorf("no connection %q in the operation (have: %s)", name, strings.Join(paths, ", "))
+}
+
+// ensureField returns the field of set with the given name, adding it when missing.
+func (p *Pager) ensureField(set *ast.SelectionSet, name string) *ast.Field {
+	for _, sel := range *set {
+		if f, ok := sel.(*ast.Field); ok && f.Name == name {
+			return f
+		}
+	}
+
+	f := &ast.Field{Name: name, Alias: name}
+	*set = append(*set, f)
+	p.modified = true
+
+	return f
+}
+
+// Connection returns the response path of the connection being paged.
+func (p *Pager) Connection() string {
+	return strings.Join(p.conn.path, ".")
+}
+
+// Page returns the number of the current page, counting from the earliest page reached.
+func (p *Pager) Page() int {
+	return p.offset - p.lowest + 1
+}
+
+// Request returns the operation for the current page. The original query text
+// is kept while the cursor moves through a variable and no pageInfo field had
+// to be added; otherwise the modified document is formatted.
+func (p *Pager) Request() *client.Request {
+	req := &client.Request{
+		Query:         p.query,
+		OperationName: p.operationName,
+	}
+
+	if p.modified {
+		var buf bytes.Buffer
+
+		formatter.NewFormatter(&buf, formatter.WithIndent("  ")).FormatQueryDocument(p.doc)
+		req.Query = strings.TrimSpace(buf.String())
+	}
+	if len(p.variables) > 0 {
+		req.Variables = maps.Clone(p.variables)
+	}
+
+	return req
+}
+
+// Next moves to the page after the one in data, the response data of the current page.
+func (p *Pager) Next(data json.RawMessage) (*client.Request, error) {
+	fields, err := p.pageInfo(data, p.conn.hasNextPage)
+	if err != nil {
+		return nil, err
+	}
+
+	info := readPageInfo(p.conn, fields)
+	if !info.HasNextPage {
+		return nil, ErrNoNextPage
+	}
+
+	if info.EndCursor == nil {
+		return nil, fmt.Errorf("%s has a next page but no endCursor", p.Connection())
+	}
+
+	if !p.pos.backward && p.pos.cursor != nil && *p.pos.cursor == *info.EndCursor {
+		return nil, fmt.Errorf("%s: cursor did not advance past %q", p.Connection(), *info.EndCursor)
+	}
+
+	p.visited = append(p.visited, p.pos)
+	p.move(position{cursor: info.EndCursor})
+	p.offset++
+
+	return p.Request(), nil
+}
+
+// Prev moves back to the page before the one in data, the response data of
+// the current page. Pages moved forward from are fetched again with the same
+// cursor; before those, the connection is paged backward with last and before
+// when it supports them.
+func (p *Pager) Prev(data json.RawMessage) (*client.Request, error) {
+	if last := len(p.visited) - 1; last >= 0 {
+		p.move(p.visited[last])
+		p.visited = p.visited[:last]
+		p.offset--
+
+		return p.Request(), nil
+	}
+
+	if !p.conn.backward {
+		return nil, ErrNoPrevPage
+	}
+
+	fields, err := p.pageInfo(data, p.backwardFields()...)
+	if err != nil {
+		return nil, err
+	}
+
+	info := readPageInfo(p.conn, fields)
+	if p.isFirst(info) {
+		return nil, ErrNoPrevPage
+	}
+
+	if p.pos.backward && p.pos.cursor != nil && *p.pos.cursor == *info.StartCursor {
+		return nil, fmt.Errorf("%s: cursor did not move back past %q", p.Connection(), *info.StartCursor)
+	}
+
+	p.move(position{cursor: info.StartCursor, backward: true})
+	p.offset--
+	p.lowest = min(p.lowest, p.offset)
+
+	return p.Request(), nil
+}
+
+// backwardFields returns the keys of startCursor and hasPreviousPage, adding
+// them to the selection when missing. The current page then has to be fetched
+// again with them.
+func (p *Pager) backwardFields() []string {
+	pageInfo := selectedField(p.conn.field.SelectionSet, "pageInfo")
+	p.conn.startCursor = p.ensureField(&pageInfo.SelectionSet, "startCursor").Alias
+	p.conn.hasPreviousPage = p.ensureField(&pageInfo.SelectionSet, "hasPreviousPage").Alias
+
+	return []string{p.conn.startCursor, p.conn.hasPreviousPage}
+}
+
+// isFirst reports whether the current page, with info, is known to be the
+// first. Servers may report no previous page for a page fetched with after, so
+// only a page fetched without a cursor or backward is known to be the first.
+func (p *Pager) isFirst(info *PageInfo) bool {
+	return info.StartCursor == nil || (!info.HasPreviousPage && (p.pos.backward || p.pos.cursor == nil))
+}
+
+// PageInfo is the pagination state of a connection.
+type PageInfo struct {
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	HasNextPage     bool    `json:"hasNextPage"`
+}
+
+// PageInfo reads the pageInfo of the connection from data.
+func (p *Pager) PageInfo(data json.RawMessage) (*PageInfo, error) {
+	fields, err := p.pageInfo(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return readPageInfo(p.conn, fields), nil
+}
+
+// pageInfo returns the pageInfo fields of the connection in data, which must
+// have the given keys.
+func (p *Pager) pageInfo(data json.RawMessage, keys ...string) (map[string]json.RawMessage, error) {
+	obj, err := p.object(data)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok := obj[p.conn.pageInfo]
+	if !ok {
+		return nil, ErrNoPageInfo
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return nil, ErrNoPageInfo
+	}
+
+	for _, key := range keys {
+		if _, ok := fields[key]; !ok {
+			return nil, ErrNoPageInfo
+		}
+	}
+
+	return fields, nil
+}
+
+func readPageInfo(conn *connection, fields map[string]json.RawMessage) *PageInfo {
+	info := &PageInfo{}
+	_ = json.Unmarshal(fields[conn.endCursor], &info.EndCursor)
+	_ = json.Unmarshal(fields[conn.hasNextPage], &info.HasNextPage)
+
+	if conn.startCursor != "" {
+		_ = json.Unmarshal(fields[conn.startCursor], &info.StartCursor)
+		_ = json.Unmarshal(fields[conn.hasPreviousPage], &info.HasPreviousPage)
+	}
+
+	return info
+}
+
+// Nodes returns the nodes of the connection in data, from edges { node } or nodes.
+func (p *Pager) Nodes(data json.RawMessage) ([]json.RawMessage, error) {
+	obj, err := p.object(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sel := range p.conn.field.SelectionSet {
+		f, ok := sel.(*ast.Field)
+		if !ok {
+			continue
+		}
+
+		switch f.Name {
+		case "nodes":
+			var nodes []json.RawMessage
+			if err := json.Unmarshal(obj[f.Alias], &nodes); err != nil {
+				return nil, fmt.Errorf("decode %s.%s: %w", p.Connection(), f.Alias, err)
+			}
+
+			return nodes, nil
+		case "edges":
+			node := selectedField(f.SelectionSet, "node")
+			if node == nil {
+				continue
+			}
+
+			var edges []map[string]json.RawMessage
+			if err := json.Unmarshal(obj[f.Alias], &edges); err != nil {
+				return nil, fmt.Errorf("decode %s.%s: %w", p.Connection(), f.Alias, err)
+			}
+
+			nodes := make([]json.RawMessage, 0, len(edges))
+			for _, e := range edges {
+				nodes = append(nodes, e[node.Alias])
+			}
+
+			return nodes, nil
+		}
+	}
+
+	return nil, fmt.Errorf("select edges { node { ... } } or nodes { ... } on %s", p.Connection())
+}
+
+func selectedField(set ast.SelectionSet, name string) *ast.Field {
+	for _, sel := range set {
+		if f, ok := sel.(*ast.Field); ok && f.Name == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// object returns the fields of the connection object in data.
+func (p *Pager) object(data json.RawMessage) (map[string]json.RawMessage, error) {
+	cur := data
+
+	for i, key := range p.conn.path {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(cur, &obj); err != nil || obj == nil {
+			return nil, fmt.Errorf("%s is null", strings.Join(p.conn.path[:i], "."))
+		}
+
+		cur = obj[key]
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(cur, &obj); err != nil || obj == nil {
+		return nil, fmt.Errorf("%s is null", p.Connection())
+	}
+
+	return obj, nil
+}
+
+// argument returns the cursor given to the argument name, or to the variable
+// it references.
+func (p *Pager) argument(name string) *string {
+	arg := p.conn.field.Arguments.ForName(name)
+	if arg == nil {
+		return nil
+	}
+
+	if arg.Value.Kind == ast.Variable {
+		if s, ok := p.variables[arg.Value.Raw].(string); ok {
+			return &s
+		}
+
+		return nil
+	}
+
+	if arg.Value.Kind == ast.StringValue {
+		return &arg.Value.Raw
+	}
+
+	return nil
+}
+
+// move sets the arguments of the connection to fetch the page at to. The page
+// size moves between first and last when the direction changes.
+func (p *Pager) move(to position) {
+	if to.backward != p.pos.backward {
+		first, last := p.size, (*ast.Value)(nil)
+		if to.backward {
+			first, last = nil, p.size
+		}
+
+		p.setArgument("first", first)
+		p.setArgument("last", last)
+		p.setArgument("after", nil)
+		p.setArgument("before", nil)
+	}
+
+	var cursor *ast.Value
+	if to.cursor != nil {
+		cursor = &ast.Value{Kind: ast.StringValue, Raw: *to.cursor}
+	}
+
+	if to.backward {
+		p.setArgument("before", cursor)
+	} else {
+		p.setArgument("after", cursor)
+	}
+
+	p.pos = to
+}
+
+// setArgument sets the argument name of the connection to value, or removes
+// it when value is nil. An argument that references a variable keeps it, and
+// the variable is set instead.
+func (p *Pager) setArgument(name string, value *ast.Value) {
+	field := p.conn.field
+
+	arg := field.Arguments.ForName(name)
+	if arg != nil && arg.Value.Kind == ast.Variable {
+		var v any
+		if value != nil {
+			v, _ = value.Value(p.variables)
+		}
+
+		p.variables[arg.Value.Raw] = v
+
+		return
+	}
+
+	switch {
+	case value == nil && arg == nil:
+		return
+	case value == nil:
+		field.Arguments = deleteArgument(field.Arguments, name)
+	case arg != nil:
+		arg.Value = value
+	default:
+		field.Arguments = append(field.Arguments, &ast.Argument{Name: name, Value: value})
+	}
+
+	p.modified = true
+}
+
+func deleteArgument(args ast.ArgumentList, name string) ast.ArgumentList {
+	out := args[:0]
+
+	for _, a := range args {
+		if a.Name != name {
+			out = append(out, a)
+		}
+	}
+
+	return out
+}
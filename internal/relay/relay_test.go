@@ -0,0 +1,372 @@
+package relay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+const testSDL = `
+type Query {
+  users(first: Int, after: String, last: Int, before: String): UserConnection!
+  tags(last: Int, before: String): TagConnection
+  viewer: Viewer
+}
+
+type Viewer {
+  friends(first: Int, after: String): UserConnection
+}
+
+type UserConnection {
+  edges: [UserEdge!]!
+  nodes: [User!]!
+  pageInfo: PageInfo!
+}
+
+type UserEdge {
+  cursor: String!
+  node: User!
+}
+
+type User {
+  id: ID!
+  name: String!
+}
+
+type TagConnection {
+  edges: [String!]!
+  pageInfo: PageInfo!
+}
+
+type PageInfo {
+  startCursor: String
+  endCursor: String
+  hasNextPage: Boolean!
+  hasPreviousPage: Boolean!
+}
+`
+
+func testSchema(t *testing.T) *ast.Schema {
+	t.Helper()
+
+	s, err := gqlparser.LoadSchema(&ast.Source{Name: "test.graphql", Input: testSDL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestIsConnection(t *testing.T) {
+	t.Parallel()
+
+	s := testSchema(t)
+
+	tests := []struct {
+		field string
+		want  bool
+	}{
+		{"users", true},
+		{"tags", true},
+		{"viewer", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsConnection(s, s.Query.Fields.ForName(tt.field)); got != tt.want {
+			t.Errorf("IsConnection(%s) = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestConnections(t *testing.T) {
+	t.Parallel()
+
+	got := Connections(testSchema(t), `{ users(first: 2) { nodes { id } } me: viewer { friends { nodes { id } } } tags { edges } }`)
+
+	// tags only pages backward
+	if want := []string{"users", "me.friends"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Connections() = %v, want %v", got, want)
+	}
+}
+
+func TestPager_Literal(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewPager(testSchema(t), &client.Request{Query: `{ users(first: 2) { edges { node { id } } } }`}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := p.Request().Query
+	if !strings.Contains(first, "pageInfo") || !strings.Contains(first, "endCursor") || !strings.Contains(first, "hasNextPage") {
+		t.Errorf("Request() should select pageInfo:\n%s", first)
+	}
+
+	req, err := p.Next(json.RawMessage(`{"users":{"edges":[],"pageInfo":{"endCursor":"c2","hasNextPage":true}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(req.Query, `users(first: 2, after: "c2")`) {
+		t.Errorf("Next() query should set the cursor:\n%s", req.Query)
+	}
+
+	if p.Page() != 2 {
+		t.Errorf("Page() = %d, want 2", p.Page())
+	}
+
+	req, err = p.Prev(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(req.Query, "after") {
+		t.Errorf("Prev() query should drop the cursor:\n%s", req.Query)
+	}
+
+	firstPage := json.RawMessage(`{"users":{"edges":[],"pageInfo":{"startCursor":"c1","endCursor":"c2","hasPreviousPage":false,"hasNextPage":true}}}`)
+	if _, err := p.Prev(firstPage); !errors.Is(err, ErrNoPrevPage) {
+		t.Errorf("Prev() on the first page error = %v, want ErrNoPrevPage", err)
+	}
+}
+
+func TestPager_Variable(t *testing.T) {
+	t.Parallel()
+
+	req := &client.Request{
+		Query:     `query Friends($n: Int, $cursor: String) { viewer { friends(first: $n, after: $cursor) { nodes { id } pageInfo { next: hasNextPage end: endCursor } } } }`,
+		Variables: map[string]any{"n": 10, "cursor": "c0"},
+	}
+
+	p, err := NewPager(testSchema(t), req, "viewer.friends")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := p.Next(json.RawMessage(`{"viewer":{"friends":{"nodes":[],"pageInfo":{"next":true,"end":"c10"}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next.Variables["cursor"] != "c10" || next.Variables["n"] != 10 {
+		t.Errorf("Next() variables = %v", next.Variables)
+	}
+
+	// The selection already has pageInfo, so the query is sent as written
+	if next.Query != req.Query {
+		t.Errorf("Next() query = %s, want the original text", next.Query)
+	}
+
+	if req.Variables["cursor"] != "c0" {
+		t.Error("Next() should not modify the original request")
+	}
+
+	prev, err := p.Prev(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if prev.Variables["cursor"] != "c0" {
+		t.Errorf("Prev() variables = %v", prev.Variables)
+	}
+}
+
+func TestPager_Backward(t *testing.T) {
+	t.Parallel()
+
+	req := &client.Request{
+		Query:     `query Users($n: Int, $after: String) { users(first: $n, after: $after) { nodes { id } pageInfo { startCursor endCursor hasPreviousPage hasNextPage } } }`,
+		Variables: map[string]any{"n": 2, "after": "c4"},
+	}
+
+	p, err := NewPager(testSchema(t), req, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := p.Request().Query; got != req.Query {
+		t.Errorf("Request() query = %s, want the original text", got)
+	}
+
+	// Before the page the pager started on, the connection is paged backward
+	prev, err := p.Prev(json.RawMessage(`{"users":{"nodes":[],"pageInfo":{"startCursor":"c5","endCursor":"c6","hasPreviousPage":false,"hasNextPage":true}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(prev.Query, `users(first: $n, after: $after, last: 2, before: "c5")`) {
+		t.Errorf("Prev() query should page backward:\n%s", prev.Query)
+	}
+
+	if prev.Variables["n"] != nil || prev.Variables["after"] != nil {
+		t.Errorf("Prev() variables = %v, want first and after cleared", prev.Variables)
+	}
+
+	if p.Page() != 1 {
+		t.Errorf("Page() = %d, want 1", p.Page())
+	}
+
+	if _, err := p.Prev(json.RawMessage(`{"users":{"nodes":[],"pageInfo":{"startCursor":"c3","endCursor":"c4","hasPreviousPage":false,"hasNextPage":true}}}`)); !errors.Is(err, ErrNoPrevPage) {
+		t.Errorf("Prev() on the first page error = %v, want ErrNoPrevPage", err)
+	}
+
+	next, err := p.Next(json.RawMessage(`{"users":{"nodes":[],"pageInfo":{"startCursor":"c3","endCursor":"c4","hasPreviousPage":false,"hasNextPage":true}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(next.Query, "last") || strings.Contains(next.Query, "before") {
+		t.Errorf("Next() query should page forward:\n%s", next.Query)
+	}
+
+	if fmt.Sprint(next.Variables["n"]) != "2" || next.Variables["after"] != "c4" {
+		t.Errorf("Next() variables = %v", next.Variables)
+	}
+
+	if p.Page() != 2 {
+		t.Errorf("Page() = %d, want 2", p.Page())
+	}
+
+	// Pages moved forward from are fetched again
+	prev, err = p.Prev(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(prev.Query, `before: "c5"`) || p.Page() != 1 {
+		t.Errorf("Prev() page %d query:\n%s", p.Page(), prev.Query)
+	}
+}
+
+func TestPager_PrevPageInfo(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewPager(testSchema(t), &client.Request{Query: `{ users(first: 2, after: "c2") { nodes { id } } }`}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if q := p.Request().Query; strings.Contains(q, "startCursor") {
+		t.Errorf("Request() should only select the pageInfo fields of paging forward:\n%s", q)
+	}
+
+	// The fields of paging backward are selected when first needed
+	if _, err := p.Prev(json.RawMessage(`{"users":{"nodes":[],"pageInfo":{"endCursor":"c4","hasNextPage":true}}}`)); !errors.Is(err, ErrNoPageInfo) {
+		t.Errorf("Prev() error = %v, want ErrNoPageInfo", err)
+	}
+
+	if q := p.Request().Query; !strings.Contains(q, "startCursor") || !strings.Contains(q, "hasPreviousPage") {
+		t.Errorf("Request() should select the pageInfo fields of paging backward:\n%s", q)
+	}
+}
+
+func TestPager_Next(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewPager(testSchema(t), &client.Request{Query: `{ users(first: 2, after: "c2") { nodes { id } } }`}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"last page", `{"users":{"pageInfo":{"endCursor":"c4","hasNextPage":false}}}`, ErrNoNextPage},
+		{"no pageInfo", `{"users":{"nodes":[]}}`, ErrNoPageInfo},
+	}
+
+	for _, tt := range tests {
+		if _, err := p.Next(json.RawMessage(tt.data)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Next() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := p.Next(json.RawMessage(`{"users":{"pageInfo":{"endCursor":"c2","hasNextPage":true}}}`)); err == nil {
+		t.Error("Next() expected error when the cursor does not advance")
+	}
+
+	if _, err := p.Next(json.RawMessage(`{"users":null}`)); err == nil {
+		t.Error("Next() expected error for a null connection")
+	}
+}
+
+func TestPager_Nodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query string
+		data  string
+		want  string
+	}{
+		{
+			name:  "edges",
+			query: `{ users(first: 2) { edges { cursor n: node { id } } } }`,
+			data:  `{"users":{"edges":[{"cursor":"a","n":{"id":"1"}},{"cursor":"b","n":{"id":"2"}}]}}`,
+			want:  `{"id":"1"}|{"id":"2"}`,
+		},
+		{
+			name:  "nodes",
+			query: `{ users(first: 2) { nodes { name id } } }`,
+			data:  `{"users":{"nodes":[{"name":"b","id":"2"}]}}`,
+			want:  `{"name":"b","id":"2"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewPager(testSchema(t), &client.Request{Query: tt.query}, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			nodes, err := p.Nodes(json.RawMessage(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0, len(nodes))
+			for _, n := range nodes {
+				got = append(got, string(n))
+			}
+
+			if strings.Join(got, "|") != tt.want {
+				t.Errorf("Nodes() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPager_Errors(t *testing.T) {
+	t.Parallel()
+
+	s := testSchema(t)
+
+	tests := []struct {
+		name  string
+		query string
+		field string
+	}{
+		{"invalid", `{ nope }`, ""},
+		{"no connection", `{ viewer { __typename } }`, ""},
+		{"unknown connection", `{ users(first: 1) { nodes { id } } }`, "viewer.friends"},
+		{"backward only", `{ tags(last: 1) { edges } }`, ""},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewPager(s, &client.Request{Query: tt.query}, tt.field); err == nil {
+			t.Errorf("%s: NewPager() expected error", tt.name)
+		}
+	}
+}
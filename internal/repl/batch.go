@@ -0,0 +1,100 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+// cmdBatch starts collecting operations to send in a single request.
+func (r *REPL) cmdBatch() error {
+	r.batching = true
+	r.batch = nil
+
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	fmt.Println(gray("Enter operations, then 'end' to send them in one request or 'cancel' to drop them."))
+
+	return nil
+}
+
+// batchInput handles a line typed while a batch is being collected.
+func (r *REPL) batchInput(input string) error {
+	switch input {
+	case "end":
+		return r.sendBatch()
+	case "cancel":
+		r.batching = false
+		r.batch = nil
+
+		fmt.Println("Batch canceled.")
+
+		return nil
+	}
+
+	if !isGraphQL(input) {
+		return fmt.Errorf("only operations can be added to a batch ('end' sends it, 'cancel' drops it)")
+	}
+
+	r.batch = append(r.batch, &client.Request{Query: input})
+
+	return nil
+}
+
+// sendBatch sends the collected operations in one request, records each
+// response in the history and prints them in order.
+func (r *REPL) sendBatch() error {
+	reqs := r.batch
+	r.batching = false
+	r.batch = nil
+
+	if len(reqs) == 0 {
+		return fmt.Errorf("the batch is empty")
+	}
+
+	for _, req := range reqs {
+		if !r.resolveDocument(req) {
+			warnUntrusted()
+		}
+	}
+
+	start := time.Now()
+
+	resps, err := r.client.ExecuteBatch(context.Background(), reqs)
+	if err != nil {
+		return fmt.Errorf("execute batch: %w", err)
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	for i, resp := range resps {
+		r.record(r.client, start, reqs[i], resp)
+
+		fmt.Println(cyan(fmt.Sprintf("#%d", i+1)), summarizeQuery(reqs[i].Query))
+
+		// The responses share the timing of the HTTP exchange, shown once at the end
+		shown := *resp
+		shown.Timing = nil
+
+		if err := r.printResponse(&shown); err != nil {
+			return err
+		}
+	}
+
+	summary := fmt.Sprintf("%d operations in one request", len(reqs))
+	if t := resps[0].Timing; t != nil {
+		summary += " (" + t.Total.Round(time.Millisecond).String() + ")"
+	}
+
+	fmt.Println(gray(summary))
+
+	if r.timing && resps[0].Timing != nil {
+		fmt.Println(gray(resps[0].Timing.String()))
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package repl
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+func TestBatch(t *testing.T) {
+	t.Parallel()
+
+	var batches []int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []client.Request
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		batches = append(batches, len(reqs))
+
+		resps := make([]string, len(reqs))
+		for i, req := range reqs {
+			data, _ := json.Marshal(req.Query)
+			resps[i] = `{"data":{"query":` + string(data) + `}}`
+		}
+
+		_, _ = io.WriteString(w, "["+strings.Join(resps, ",")+"]")
+	}))
+	defer srv.Close()
+
+	r := New(client.New(srv.URL), &ast.Schema{})
+
+	for _, input := range []string{"batch", "{ a }", "{ b }"} {
+		if err := r.execute(input); err != nil {
+			t.Fatalf("execute(%q) error = %v", input, err)
+		}
+	}
+
+	if err := r.execute("help"); err == nil {
+		t.Error("execute() should only accept operations in a batch")
+	}
+
+	if prefix, _ := r.livePrefix(); prefix != "batch(2)> " {
+		t.Errorf("livePrefix() = %q", prefix)
+	}
+
+	if err := r.execute("end"); err != nil {
+		t.Fatalf("execute(end) error = %v", err)
+	}
+
+	if len(batches) != 1 || batches[0] != 2 {
+		t.Errorf("batches = %v, want one batch of 2", batches)
+	}
+
+	entries := r.history.since(0)
+	if len(entries) != 2 || !strings.Contains(string(entries[1].response.Data), "{ b }") {
+		t.Errorf("history has %d entries", len(entries))
+	}
+
+	if r.batching {
+		t.Error("the batch should be closed after end")
+	}
+
+	_ = r.execute("batch")
+	_ = r.execute("{ c }")
+
+	if err := r.execute("cancel"); err != nil || r.batching || len(batches) != 1 {
+		t.Errorf("cancel: err = %v, batching = %v, batches = %v", err, r.batching, batches)
+	}
+}
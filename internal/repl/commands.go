@@ -2,7 +2,6 @@ package repl
 
 import (
 	"bufio"
-	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -31,6 +30,20 @@ func (r *REPL) cmdHelp() error {
 		{"show", "", "Show schema info (types, queries, mutations, federation)"},
 		{"desc", "describe", "Describe a type or field"},
 		{"call", "", "Call a query or mutation interactively"},
+		{"header", "", "Manage request headers (set, unset, list)"},
+		{"use", "", "Switch to a profile or endpoint URL"},
+		{"cookies", "", "List or clear the cookies kept with --cookies (list, clear)"},
+		{"set", "", "Show or change settings (output, history, timing, trace)"},
+		{"history", "", "List, show, save or diff previous responses"},
+		{"trace", "", "Show the resolver trace and query plan of a response"},
+		{"export", "", "Export a request as curl, httpie or go (--redact hides secrets)"},
+		{"import", "", "Import requests from a curl command or .http file"},
+		{"run", "", "List or run imported requests"},
+		{"watch", "", "Re-run the last query (or a query file) every interval until Ctrl+C"},
+		{"batch", "", "Collect operations until 'end' and send them in one request ('cancel' drops them)"},
+		{"next", "", "Re-run the last query on the next page of a Relay connection"},
+		{"prev", "", "Re-run the last query on the previous page of a Relay connection"},
+		{"let", "", "Capture a value from the last response (let id = .user.id), used as {{id}}"},
 		{"exit", "quit, q", "Exit the REPL"},
 	}
 
@@ -333,9 +346,9 @@ func (r *REPL) executeField(opType string, field *ast.FieldDefinition) error {
 	// Build and execute query
 	query := r.buildQueryWithSelection(opType, field, args, selection)
 
-	resp, err := r.client.Execute(context.Background(), &client.Request{Query: query})
+	resp, err := r.send(&client.Request{Query: query, Files: uploadFiles(field, args)})
 	if err != nil {
-		return fmt.Errorf("execute: %w", err)
+		return err
 	}
 
 	fmt.Println()
@@ -365,6 +378,13 @@ func (r *REPL) readArgs(argDefs ast.ArgumentDefinitionList) (map[string]any, err
 			req = yellow(" (required)")
 		}
 
+		if isUpload(arg.Type) {
+			typeStr += " file path"
+			if arg.Type.Elem != nil {
+				typeStr += "s, comma-separated"
+			}
+		}
+
 		fmt.Printf("  %s %s%s: ", cyan(arg.Name), gray(typeStr), req)
 
 		input, err := reader.ReadString('\n')
@@ -372,8 +392,8 @@ func (r *REPL) readArgs(argDefs ast.ArgumentDefinitionList) (map[string]any, err
 			return nil, errInputCanceled
 		}
 
-		input = strings.TrimSpace(input)
-		if input == "" {
+		value, ok := r.parseInput(input, arg.Type)
+		if !ok {
 			if arg.Type.NonNull && arg.DefaultValue == nil {
 				fmt.Println("    Required field.")
 
@@ -383,13 +403,34 @@ func (r *REPL) readArgs(argDefs ast.ArgumentDefinitionList) (map[string]any, err
 			continue
 		}
 
-		result[arg.Name] = r.parseValue(input, arg.Type)
+		result[arg.Name] = value
 	}
 
 	return result, nil
 }
 
+// parseInput parses the input for an argument of type t. It reports false
+// when the input gives no value, including an Upload argument without file
+// paths, which would declare a variable with no file part.
+func (r *REPL) parseInput(input string, t *ast.Type) (any, bool) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, false
+	}
+
+	v := r.parseValue(input, t)
+	if paths, ok := v.(upload); ok && len(paths) == 0 {
+		return nil, false
+	}
+
+	return v, true
+}
+
 func (r *REPL) parseValue(input string, t *ast.Type) any {
+	if isUpload(t) {
+		return parseUpload(input)
+	}
+
 	// Strip quotes if provided
 	input = strings.Trim(input, "\"'")
 
@@ -640,7 +681,7 @@ func makeSelectedMap(selected []string) map[string]bool {
 func (r *REPL) buildQueryWithSelection(opType string, field *ast.FieldDefinition, args map[string]any, selection []selectedField) string {
 	var sb strings.Builder
 
-	sb.WriteString(opType + " {\n  " + field.Name)
+	sb.WriteString(opType + uploadVariables(field, args) + " {\n  " + field.Name)
 
 	if len(args) > 0 {
 		sb.WriteString("(")
@@ -651,6 +692,14 @@ func (r *REPL) buildQueryWithSelection(opType string, field *ast.FieldDefinition
 				sb.WriteString(", ")
 			}
 
+			if _, ok := v.(upload); ok {
+				// Files are sent as variables of the multipart request
+				sb.WriteString(k + ": $" + k)
+				first = false
+
+				continue
+			}
+
 			sb.WriteString(k + ": " + r.formatArg(v, r.findArgType(field, k)))
 
 			first = false
@@ -134,3 +134,69 @@ func TestBuildQueryWithSelection_Mutation(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildQueryWithSelection_Upload(t *testing.T) {
+	t.Parallel()
+
+	r := &REPL{schema: &ast.Schema{Types: map[string]*ast.Definition{}}}
+
+	field := &ast.FieldDefinition{
+		Name: "uploadMedia",
+		Type: ast.NamedType("Boolean", nil),
+		Arguments: ast.ArgumentDefinitionList{
+			{Name: "avatar", Type: ast.NonNullNamedType("Upload", nil)},
+			{Name: "files", Type: ast.ListType(ast.NonNullNamedType("Upload", nil), nil)},
+			{Name: "title", Type: ast.NamedType("String", nil)},
+		},
+	}
+	args := map[string]any{
+		"avatar": r.parseValue("./me.png", field.Arguments[0].Type),
+		"files":  r.parseValue("a.txt, 'b.txt'", field.Arguments[1].Type),
+		"title":  "hi",
+	}
+
+	got := r.buildQueryWithSelection("mutation", field, args, nil)
+
+	for _, want := range []string{"mutation($avatar: Upload!, $files: [Upload!]) {", "avatar: $avatar", "files: $files", `title: "hi"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("buildQueryWithSelection() = %q, should contain %q", got, want)
+		}
+	}
+
+	files := uploadFiles(field, args)
+	want := map[string]string{"avatar": "./me.png", "files.0": "a.txt", "files.1": "b.txt"}
+
+	if len(files) != len(want) {
+		t.Fatalf("uploadFiles() = %v, want %v", files, want)
+	}
+
+	for k, v := range want {
+		if files[k] != v {
+			t.Errorf("uploadFiles()[%s] = %q, want %q", k, files[k], v)
+		}
+	}
+}
+
+func TestParseInput_Upload(t *testing.T) {
+	t.Parallel()
+
+	r := &REPL{}
+	files := ast.ListType(ast.NonNullNamedType("Upload", nil), nil)
+
+	tests := []struct {
+		input  string
+		wantOK bool
+	}{
+		{input: "", wantOK: false},
+		{input: ",", wantOK: false},
+		{input: " , '' ", wantOK: false},
+		{input: "a.txt,", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		v, ok := r.parseInput(tt.input, files)
+		if ok != tt.wantOK {
+			t.Errorf("parseInput(%q) = %v, %v; want ok = %v", tt.input, v, ok, tt.wantOK)
+		}
+	}
+}
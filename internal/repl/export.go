@@ -0,0 +1,73 @@
+package repl
+
+import (
+	"fmt"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/config"
+	"github.com/sivchari/iris/internal/export"
+)
+
+// cmdExport prints a previous request as a curl or HTTPie command or a Go program.
+func (r *REPL) cmdExport(args []string) error {
+	var (
+		positional []string
+		redact     bool
+	)
+
+	for _, a := range args {
+		if a == "--redact" {
+			redact = true
+
+			continue
+		}
+
+		positional = append(positional, a)
+	}
+
+	if len(positional) == 0 {
+		return fmt.Errorf("usage: export <curl|httpie|go> [id] [--redact]")
+	}
+
+	f, err := export.ParseFormat(positional[0])
+	if err != nil {
+		return err //nolint:wrapcheck // already describes the export error
+	}
+
+	e, err := r.history.lookup(argAt(positional, 1))
+	if err != nil {
+		return err
+	}
+
+	var opts export.Options
+	if redact {
+		if opts.Redact, err = redactHeaders(); err != nil {
+			return err
+		}
+	}
+
+	// The request is rendered as the client sends it to the endpoint it was sent to
+	wire, err := r.client.Clone(client.WithEndpoint(e.endpoint)).Wire(e.request)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	out, err := export.Render(f, &export.Request{Headers: e.headers, Wire: wire}, opts)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	fmt.Print(out)
+
+	return nil
+}
+
+// redactHeaders returns the default secret headers plus those listed in the config file.
+func redactHeaders() ([]string, error) {
+	cfg, err := config.Load(config.DefaultPaths()...)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	return append(append([]string{}, export.DefaultRedactHeaders...), cfg.Redact...), nil
+}
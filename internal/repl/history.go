@@ -0,0 +1,283 @@
+package repl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/jsondiff"
+)
+
+const defaultHistorySize = 50
+
+// historyEntry is a request sent in this session and its response.
+type historyEntry struct {
+	id       int
+	time     time.Time
+	duration time.Duration
+	endpoint string
+	headers  map[string]string
+	request  *client.Request
+	response *client.Response
+}
+
+// history keeps the most recent responses of the session.
+type history struct {
+	size    int
+	lastID  int
+	entries []*historyEntry
+}
+
+func newHistory(size int) *history {
+	return &history{size: size}
+}
+
+// add records an entry and assigns its id, dropping the oldest entries beyond the size limit.
+func (h *history) add(e *historyEntry) {
+	h.lastID++
+	e.id = h.lastID
+	h.entries = append(h.entries, e)
+	h.trim()
+}
+
+func (h *history) trim() {
+	if over := len(h.entries) - h.size; over > 0 {
+		h.entries = h.entries[over:]
+	}
+}
+
+func (h *history) last() *historyEntry {
+	if len(h.entries) == 0 {
+		return nil
+	}
+
+	return h.entries[len(h.entries)-1]
+}
+
+// since returns the entries recorded after the entry with the given id.
+func (h *history) since(id int) []*historyEntry {
+	for i, e := range h.entries {
+		if e.id > id {
+			return h.entries[i:]
+		}
+	}
+
+	return nil
+}
+
+func (h *history) get(id int) (*historyEntry, error) {
+	for _, e := range h.entries {
+		if e.id == id {
+			return e, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no response #%d in history", id)
+}
+
+// lookup resolves an entry id argument; an empty argument means the last entry.
+func (h *history) lookup(arg string) (*historyEntry, error) {
+	if arg == "" {
+		if e := h.last(); e != nil {
+			return e, nil
+		}
+
+		return nil, fmt.Errorf("history is empty")
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid history id: %s", arg)
+	}
+
+	return h.get(id)
+}
+
+// cmdHistory lists, shows, saves or diffs previous responses.
+func (r *REPL) cmdHistory(args []string) error {
+	if len(args) == 0 {
+		return r.listHistory()
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		return r.listHistory()
+	case "show":
+		e, err := r.history.lookup(argAt(args, 1))
+		if err != nil {
+			return err
+		}
+
+		return r.printResponse(e.response)
+	case "save":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: history save [id] <file>")
+		}
+
+		id, path := "", args[1]
+		if len(args) > 2 {
+			id, path = args[1], args[2]
+		}
+
+		return r.saveHistory(id, path)
+	case "diff":
+		return r.diffHistory(argAt(args, 1), argAt(args, 2))
+	default:
+		return fmt.Errorf("unknown: %s (use: list, show, save, diff)", args[0])
+	}
+}
+
+func argAt(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+
+	return ""
+}
+
+func (r *REPL) listHistory() error {
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	if len(r.history.entries) == 0 {
+		fmt.Println("History is empty.")
+
+		return nil
+	}
+
+	for _, e := range r.history.entries {
+		status := ""
+		if n := len(e.response.Errors); n > 0 {
+			status = red(fmt.Sprintf(" (%d errors)", n))
+		}
+
+		fmt.Printf("%4d  %s  %8s  %s%s\n",
+			e.id,
+			gray(e.time.Format(time.TimeOnly)),
+			e.duration.Round(time.Millisecond),
+			summarizeQuery(e.request.Query),
+			status,
+		)
+	}
+
+	return nil
+}
+
+// summarizeQuery collapses a query to a single line for listings.
+func summarizeQuery(q string) string {
+	const maxLen = 60
+
+	// Cut on rune boundaries, so that non-ASCII text stays valid UTF-8
+	s := []rune(strings.Join(strings.Fields(q), " "))
+	if len(s) > maxLen {
+		return string(s[:maxLen-3]) + "..."
+	}
+
+	return string(s)
+}
+
+func (r *REPL) saveHistory(id, path string) error {
+	e, err := r.history.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(e.response, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	fmt.Printf("Saved #%d to %s\n", e.id, path)
+
+	return nil
+}
+
+// diffHistory compares two responses. Without ids it compares the last two,
+// and with one id it compares that response to the last one.
+func (r *REPL) diffHistory(idA, idB string) error {
+	var a, b *historyEntry
+
+	var err error
+
+	switch {
+	case idA == "":
+		n := len(r.history.entries)
+		if n < 2 {
+			return fmt.Errorf("need at least two responses to diff")
+		}
+
+		a, b = r.history.entries[n-2], r.history.entries[n-1]
+	default:
+		if a, err = r.history.lookup(idA); err != nil {
+			return err
+		}
+
+		if b, err = r.history.lookup(idB); err != nil {
+			return err
+		}
+	}
+
+	changes, err := diffResponses(a.response, b.response)
+	if err != nil {
+		return err
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	fmt.Printf("%s #%d -> #%d\n", cyan("Diff"), a.id, b.id)
+
+	printChanges(changes)
+
+	return nil
+}
+
+func diffResponses(a, b *client.Response) ([]jsondiff.Change, error) {
+	da, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+
+	db, err := json.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+
+	changes, err := jsondiff.Compare(da, db)
+	if err != nil {
+		return nil, fmt.Errorf("diff: %w", err)
+	}
+
+	return changes, nil
+}
+
+func printChanges(changes []jsondiff.Change) {
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	if len(changes) == 0 {
+		fmt.Println("No differences.")
+
+		return
+	}
+
+	for _, c := range changes {
+		switch c.Kind {
+		case jsondiff.Added:
+			fmt.Println(green("+ " + c.Path + ": " + jsondiff.Format(c.New)))
+		case jsondiff.Removed:
+			fmt.Println(red("- " + c.Path + ": " + jsondiff.Format(c.Old)))
+		case jsondiff.Changed:
+			fmt.Printf("%s %s: %s -> %s\n", yellow("~"), c.Path, red(jsondiff.Format(c.Old)), green(jsondiff.Format(c.New)))
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package repl
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	h := newHistory(2)
+
+	if _, err := h.lookup(""); err == nil {
+		t.Error("lookup() on empty history expected error")
+	}
+
+	for range 3 {
+		h.add(&historyEntry{response: &client.Response{}})
+	}
+
+	if len(h.entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(h.entries))
+	}
+
+	if _, err := h.get(1); err == nil {
+		t.Error("get(1) expected error for dropped entry")
+	}
+
+	e, err := h.lookup("#2")
+	if err != nil || e.id != 2 {
+		t.Errorf("lookup(#2) = %v, %v", e, err)
+	}
+
+	if e, _ := h.lookup(""); e.id != 3 {
+		t.Errorf("lookup(\"\") id = %d, want 3", e.id)
+	}
+
+	h.size = 1
+	h.trim()
+
+	if len(h.entries) != 1 || h.last().id != 3 {
+		t.Errorf("trim() kept %d entries", len(h.entries))
+	}
+}
+
+func TestSummarizeQuery(t *testing.T) {
+	t.Parallel()
+
+	got := summarizeQuery("query {\n  users {\n    id\n  }\n}")
+	if want := "query { users { id } }"; got != want {
+		t.Errorf("summarizeQuery() = %q, want %q", got, want)
+	}
+
+	long := summarizeQuery("{ " + strings.Repeat("field ", 20) + "}")
+	if len(long) != 60 {
+		t.Errorf("summarizeQuery() length = %d, want 60", len(long))
+	}
+
+	wide := summarizeQuery(`{ search(text: "` + strings.Repeat("日本語", 20) + `") { id } }`)
+	if !utf8.ValidString(wide) || utf8.RuneCountInString(wide) != 60 {
+		t.Errorf("summarizeQuery() = %q, want 60 runes of valid UTF-8", wide)
+	}
+}
@@ -0,0 +1,106 @@
+package repl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/importer"
+)
+
+// cmdImport adds requests from a curl command or a .http file to the session.
+func (r *REPL) cmdImport(arg string) error {
+	kind, rest, _ := strings.Cut(arg, " ")
+	rest = strings.TrimSpace(rest)
+
+	if rest == "" {
+		return fmt.Errorf("usage: import curl <command> | import http <file>")
+	}
+
+	var (
+		reqs []*importer.Request
+		err  error
+	)
+
+	switch kind {
+	case "curl":
+		var req *importer.Request
+		if req, err = importer.ParseCurl(rest); err == nil {
+			reqs = []*importer.Request{req}
+		}
+	case "http":
+		reqs, err = importer.LoadHTTPFile(rest)
+	default:
+		return fmt.Errorf("unknown import source: %s (use: curl, http)", kind)
+	}
+
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	for _, req := range reqs {
+		r.addImported(req)
+		fmt.Printf("Imported %s (%s)\n", req.Name, req.Endpoint)
+	}
+
+	fmt.Println("Type 'run <name>' to send.")
+
+	return nil
+}
+
+// addImported stores req, replacing an earlier request with the same name.
+func (r *REPL) addImported(req *importer.Request) {
+	for i, existing := range r.imported {
+		if existing.Name == req.Name {
+			r.imported[i] = req
+
+			return
+		}
+	}
+
+	r.imported = append(r.imported, req)
+}
+
+// cmdRun sends an imported request with its endpoint and headers on top of the session headers.
+func (r *REPL) cmdRun(args []string) error {
+	if len(args) == 0 {
+		r.listImported()
+
+		return nil
+	}
+
+	for _, req := range r.imported {
+		if req.Name != args[0] {
+			continue
+		}
+
+		opts := []client.Option{client.WithEndpoint(req.Endpoint)}
+		for k, v := range req.Headers {
+			opts = append(opts, client.WithHeader(k, v))
+		}
+
+		resp, err := r.sendWith(r.client.Clone(opts...), req.Body)
+		if err != nil {
+			return err
+		}
+
+		return r.printResponse(resp)
+	}
+
+	return fmt.Errorf("imported request not found: %s", args[0])
+}
+
+func (r *REPL) listImported() {
+	if len(r.imported) == 0 {
+		fmt.Println("No imported requests. Use 'import curl <command>' or 'import http <file>'.")
+
+		return
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	for _, req := range r.imported {
+		fmt.Printf("  %s  %s  %s\n", cyan(req.Name), req.Endpoint, summarizeQuery(req.Body.Query))
+	}
+}
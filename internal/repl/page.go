@@ -0,0 +1,117 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/relay"
+)
+
+// pager pages through a connection of the last request.
+type pager struct {
+	*relay.Pager
+	// entry is the history id of the page last fetched
+	entry int
+	// warned is set once the operation was reported missing from the manifest
+	warned bool
+}
+
+// cmdNext re-runs the last request on the next page of a connection.
+func (r *REPL) cmdNext(args []string) error {
+	return r.page(argAt(args, 0), true)
+}
+
+// cmdPrev re-runs the last request on the previous page of a connection.
+func (r *REPL) cmdPrev(args []string) error {
+	return r.page(argAt(args, 0), false)
+}
+
+func (r *REPL) page(connection string, forward bool) error {
+	e := r.history.last()
+	if e == nil {
+		return fmt.Errorf("nothing to page: send a query first")
+	}
+
+	if e.endpoint != r.client.Endpoint() {
+		return fmt.Errorf("the last request was sent to %s", e.endpoint)
+	}
+
+	// A new request, or another connection, starts paging from that response
+	if r.pager == nil || r.pager.entry != e.id || (connection != "" && connection != r.pager.Connection()) {
+		p, err := relay.NewPager(r.schema, e.request, connection)
+		if err != nil {
+			return err //nolint:wrapcheck // already describes the operation
+		}
+
+		r.pager = &pager{Pager: p, entry: e.id}
+	}
+
+	req, err := r.movePage(e.response, forward)
+	if err != nil {
+		return err
+	}
+
+	r.resolvePage(req)
+
+	resp, err := r.sendResolved(r.client, req)
+	if err != nil {
+		// The pager has moved past the last response, so start over on the next call
+		r.pager = nil
+
+		return err
+	}
+
+	r.pager.entry = r.history.lastID
+
+	if err := r.printResponse(resp); err != nil {
+		return err
+	}
+
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	fmt.Println(gray(fmt.Sprintf("%s: page %d", r.pager.Connection(), r.pager.Page())))
+
+	return nil
+}
+
+func (r *REPL) movePage(resp *client.Response, forward bool) (*client.Request, error) {
+	move := r.pager.Next
+	if !forward {
+		move = r.pager.Prev
+	}
+
+	req, err := move(resp.Data)
+	if errors.Is(err, relay.ErrNoPageInfo) {
+		// The operation did not select pageInfo: fetch the current page again with it
+		current := r.pager.Request()
+		r.resolvePage(current)
+
+		if resp, err = r.client.Execute(context.Background(), current); err != nil {
+			return nil, fmt.Errorf("execute: %w", err)
+		}
+
+		req, err = move(resp.Data)
+	}
+
+	switch {
+	case errors.Is(err, relay.ErrNoNextPage):
+		return nil, fmt.Errorf("%s: already on the last page", r.pager.Connection())
+	case errors.Is(err, relay.ErrNoPrevPage):
+		return nil, fmt.Errorf("%s: already on the first page", r.pager.Connection())
+	}
+
+	return req, err //nolint:wrapcheck // already describes the connection
+}
+
+// resolvePage sets the document id of a page request from the manifest. Every
+// page sends the same operation, so an unlisted one is reported once.
+func (r *REPL) resolvePage(req *client.Request) {
+	if !r.resolveDocument(req) && !r.pager.warned {
+		warnUntrusted()
+
+		r.pager.warned = true
+	}
+}
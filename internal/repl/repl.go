@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	prompt "github.com/ktr0731/go-prompt"
@@ -16,7 +17,11 @@ import (
 	"github.com/sivchari/iris/internal/client"
 	"github.com/sivchari/iris/internal/federation"
 	_ "github.com/sivchari/iris/internal/federation/apollo" // Register Apollo Federation provider
+	"github.com/sivchari/iris/internal/filter"
 	"github.com/sivchari/iris/internal/gql"
+	"github.com/sivchari/iris/internal/importer"
+	"github.com/sivchari/iris/internal/output"
+	"github.com/sivchari/iris/internal/trusted"
 )
 
 var errExit = fmt.Errorf("exit")
@@ -28,29 +33,96 @@ type REPL struct {
 	federation *federation.Info
 	completer  *gql.Completer
 	prompt     *prompt.Prompt
+	profile    string
+	output     output.Format
+	filter     *filter.Filter
+	history    *history
+	timing     bool
+	trace      bool
+	imported   []*importer.Request
+	vars       map[string]json.RawMessage
+	pager      *pager
+	manifest   *trusted.Manifest
+	batch      []*client.Request
+	batching   bool
+	connect    func(target string) (*Connection, error)
+}
+
+// Connection is an endpoint to switch to with "use".
+type Connection struct {
+	Client *client.Client
+	// Profile is the config profile, or "" for a URL.
+	Profile string
+	// Schema is an SDL file to load instead of introspecting.
+	Schema   string
+	Manifest *trusted.Manifest
+	// Output is the output format of the profile, or of the command line.
+	Output output.Format
+}
+
+// Option configures the REPL.
+type Option func(*REPL)
+
+// WithProfile records the name of the config profile in use.
+func WithProfile(name string) Option {
+	return func(r *REPL) {
+		r.profile = name
+	}
+}
+
+// WithOutput sets the output format for responses.
+func WithOutput(f output.Format) Option {
+	return func(r *REPL) {
+		r.output = f
+	}
+}
+
+// WithTiming shows the request timing after each response.
+func WithTiming(on bool) Option {
+	return func(r *REPL) {
+		r.timing = on
+	}
+}
+
+// WithManifest sends the document id of operations listed in m instead of their text.
+func WithManifest(m *trusted.Manifest) Option {
+	return func(r *REPL) {
+		r.manifest = m
+	}
+}
+
+// WithConnect sets how "use" connects to a profile name or a URL, so that
+// the new client is built the same way as the first one.
+func WithConnect(fn func(target string) (*Connection, error)) Option {
+	return func(r *REPL) {
+		r.connect = fn
+	}
+}
+
+// WithVariables defines string variables for "{{name}}" interpolation.
+func WithVariables(vars map[string]string) Option {
+	return func(r *REPL) {
+		for k, v := range vars {
+			data, _ := json.Marshal(v)
+			r.vars[k] = data
+		}
+	}
 }
 
 // New creates a new REPL.
-func New(c *client.Client, schema *ast.Schema) *REPL {
+func New(c *client.Client, schema *ast.Schema, opts ...Option) *REPL {
 	r := &REPL{
 		client:     c,
 		schema:     schema,
 		federation: federation.Detect(schema),
 		completer:  gql.NewCompleter(schema),
+		output:     output.JSON,
+		history:    newHistory(defaultHistorySize),
+		vars:       make(map[string]json.RawMessage),
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
-
-	r.prompt = prompt.New(
-		r.executor,
-		r.completer.Complete,
-		prompt.OptionTitle("iris"),
-		prompt.OptionPrefix("iris> "),
-		prompt.OptionPrefixTextColor(prompt.Green),
-		prompt.OptionPreviewSuggestionTextColor(prompt.Blue),
-		prompt.OptionSelectedSuggestionBGColor(prompt.LightGray),
-		prompt.OptionSuggestionBGColor(prompt.DarkGray),
-		prompt.OptionMaxSuggestion(10),
-		prompt.OptionShowCompletionAtStart(),
-	)
 
 	return r
 }
@@ -69,6 +141,20 @@ func (r *REPL) Run() error {
 
 	fmt.Println()
 
+	// The prompt opens the terminal, so it is only created for interactive use
+	r.prompt = prompt.New(
+		r.executor,
+		r.complete,
+		prompt.OptionTitle("iris"),
+		prompt.OptionPrefix("iris> "),
+		prompt.OptionLivePrefix(r.livePrefix),
+		prompt.OptionPrefixTextColor(prompt.Green),
+		prompt.OptionPreviewSuggestionTextColor(prompt.Blue),
+		prompt.OptionSelectedSuggestionBGColor(prompt.LightGray),
+		prompt.OptionSuggestionBGColor(prompt.DarkGray),
+		prompt.OptionMaxSuggestion(10),
+		prompt.OptionShowCompletionAtStart(),
+	)
 	r.prompt.Run()
 
 	return nil
@@ -79,6 +165,23 @@ func (r *REPL) Close() error {
 	return nil
 }
 
+// complete delegates to the current completer, which is replaced on endpoint switch.
+func (r *REPL) complete(d prompt.Document) []prompt.Suggest {
+	return r.completer.Complete(d)
+}
+
+func (r *REPL) livePrefix() (string, bool) {
+	if r.batching {
+		return fmt.Sprintf("batch(%d)> ", len(r.batch)), true
+	}
+
+	if r.profile == "" {
+		return "", false
+	}
+
+	return "iris:" + r.profile + "> ", true
+}
+
 func (r *REPL) executor(input string) {
 	input = strings.TrimSpace(input)
 	if input == "" {
@@ -97,6 +200,37 @@ func (r *REPL) executor(input string) {
 }
 
 func (r *REPL) execute(input string) error {
+	input, err := r.interpolate(input)
+	if err != nil {
+		return err
+	}
+
+	if r.batching {
+		return r.batchInput(input)
+	}
+
+	// Variable capture, whose expression may itself contain '|'
+	if input == "let" || strings.HasPrefix(input, "let ") {
+		return r.cmdLet(input)
+	}
+
+	// Response filter: "<input> | <expr>"
+	input, expr := splitFilter(input)
+	if expr != "" {
+		f, err := filter.Compile(expr)
+		if err != nil {
+			return err //nolint:wrapcheck // already describes the filter error
+		}
+
+		r.filter = f
+		defer func() { r.filter = nil }()
+
+		// A bare filter slices the last response
+		if input == "" {
+			return r.printLast()
+		}
+	}
+
 	// Raw GraphQL query
 	if isGraphQL(input) {
 		return r.executeRaw(input)
@@ -118,6 +252,33 @@ func (r *REPL) execute(input string) error {
 		return r.cmdDesc(args)
 	case "call":
 		return r.cmdCall(args)
+	case "header":
+		return r.cmdHeader(args)
+	case "use":
+		return r.cmdUse(args)
+	case "cookies":
+		return r.cmdCookies(args)
+	case "set":
+		return r.cmdSet(args)
+	case "history":
+		return r.cmdHistory(args)
+	case "trace":
+		return r.cmdTrace(args)
+	case "export":
+		return r.cmdExport(args)
+	case "import":
+		// Keep the quoting of pasted curl commands intact
+		return r.cmdImport(strings.TrimSpace(strings.TrimPrefix(input, cmd)))
+	case "run":
+		return r.cmdRun(args)
+	case "watch":
+		return r.cmdWatch(args)
+	case "batch":
+		return r.cmdBatch()
+	case "next":
+		return r.cmdNext(args)
+	case "prev":
+		return r.cmdPrev(args)
 	case "exit", "quit", "q":
 		return errExit
 	default:
@@ -133,15 +294,116 @@ func isGraphQL(s string) bool {
 		strings.HasPrefix(s, "mutation")
 }
 
+// splitFilter splits input at the first '|' outside of strings and brackets.
+func splitFilter(input string) (rest, expr string) {
+	depth := 0
+	inString := false
+
+	for i := 0; i < len(input); i++ {
+		switch c := input[i]; {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '(' || c == '[':
+			depth++
+		case c == '}' || c == ')' || c == ']':
+			depth--
+		case c == '|' && depth == 0:
+			return strings.TrimSpace(input[:i]), strings.TrimSpace(input[i+1:])
+		}
+	}
+
+	return input, ""
+}
+
+func (r *REPL) printLast() error {
+	e := r.history.last()
+	if e == nil {
+		return fmt.Errorf("no previous response")
+	}
+
+	return r.printResponse(e.response)
+}
+
 func (r *REPL) executeRaw(query string) error {
-	resp, err := r.client.Execute(context.Background(), &client.Request{Query: query})
+	resp, err := r.send(&client.Request{Query: query})
 	if err != nil {
-		return fmt.Errorf("execute: %w", err)
+		return err
 	}
 
 	return r.printResponse(resp)
 }
 
+// send executes a request and records it in the history.
+func (r *REPL) send(req *client.Request) (*client.Response, error) {
+	return r.sendWith(r.client, req)
+}
+
+// sendWith executes a request with the given client and records it in the history.
+func (r *REPL) sendWith(c *client.Client, req *client.Request) (*client.Response, error) {
+	if !r.resolveDocument(req) {
+		warnUntrusted()
+	}
+
+	return r.sendResolved(c, req)
+}
+
+// sendResolved sends req, with its document already resolved, and records it in the history.
+func (r *REPL) sendResolved(c *client.Client, req *client.Request) (*client.Response, error) {
+	start := time.Now()
+
+	resp, err := c.Execute(context.Background(), req)
+	if err != nil {
+		return nil, fmt.Errorf("execute: %w", err)
+	}
+
+	r.record(c, start, req, resp)
+
+	return resp, nil
+}
+
+// record adds a request sent with c at start and its response to the history.
+func (r *REPL) record(c *client.Client, start time.Time, req *client.Request, resp *client.Response) {
+	duration := time.Since(start)
+	if resp.Timing != nil {
+		duration = resp.Timing.Total
+	}
+
+	r.history.add(&historyEntry{
+		time:     start,
+		duration: duration,
+		endpoint: c.Endpoint(),
+		headers:  c.Headers(),
+		request:  req,
+		response: resp,
+	})
+}
+
+// resolveDocument sets the document id of req from the manifest. It reports
+// false when the operation is not listed and the query text is sent instead.
+func (r *REPL) resolveDocument(req *client.Request) bool {
+	if r.manifest == nil || req.DocumentID != "" {
+		return true
+	}
+
+	id, ok := r.manifest.Lookup(req.Query)
+	if ok {
+		req.DocumentID = id
+	}
+
+	return ok
+}
+
+func warnUntrusted() {
+	yellow := color.New(color.FgYellow).SprintFunc()
+	fmt.Fprintln(os.Stderr, yellow("Warning:"), "the operation is not in the trusted documents manifest; sending the query text")
+}
+
 func (r *REPL) printResponse(resp *client.Response) error {
 	if len(resp.Errors) > 0 {
 		red := color.New(color.FgRed).SprintFunc()
@@ -154,14 +416,48 @@ func (r *REPL) printResponse(resp *client.Response) error {
 		fmt.Println()
 	}
 
-	if resp.Data != nil {
-		var data any
-		if err := json.Unmarshal(resp.Data, &data); err != nil {
-			return fmt.Errorf("unmarshal: %w", err)
+	if err := r.printData(resp.Data); err != nil {
+		return err
+	}
+
+	if r.timing && resp.Timing != nil {
+		gray := color.New(color.FgHiBlack).SprintFunc()
+		fmt.Println(gray(resp.Timing.String()))
+	}
+
+	if r.trace {
+		if err := printTrace(resp); err != nil && !errors.Is(err, errNoTrace) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *REPL) printData(data []byte) error {
+	if r.filter != nil {
+		return r.printFiltered(data)
+	}
+
+	if data != nil {
+		if err := output.Write(os.Stdout, r.output, data); err != nil {
+			return fmt.Errorf("print: %w", err)
 		}
+	}
 
-		out, _ := json.MarshalIndent(data, "", "  ")
-		fmt.Println(string(out))
+	return nil
+}
+
+func (r *REPL) printFiltered(data []byte) error {
+	results, err := r.filter.Apply(data)
+	if err != nil {
+		return err //nolint:wrapcheck // already describes the filter error
+	}
+
+	for _, v := range results {
+		if err := output.Write(os.Stdout, r.output, v); err != nil {
+			return fmt.Errorf("print: %w", err)
+		}
 	}
 
 	return nil
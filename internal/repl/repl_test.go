@@ -0,0 +1,66 @@
+package repl
+
+import (
+	"testing"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/trusted"
+)
+
+func TestSplitFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		wantRest string
+		wantExpr string
+	}{
+		{"{ users { id } }", "{ users { id } }", ""},
+		{"{ users { email } } | .users[].email", "{ users { email } }", ".users[].email"},
+		{"{ users { id } } | .users[] | .id", "{ users { id } }", ".users[] | .id"},
+		{`{ search(q: "a|b") { id } } | .search`, `{ search(q: "a|b") { id } }`, ".search"},
+		{`{ search(q: "\"|") { id } }`, `{ search(q: "\"|") { id } }`, ""},
+		{"| .users[0]", "", ".users[0]"},
+		{"call users | .users", "call users", ".users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			rest, expr := splitFilter(tt.input)
+			if rest != tt.wantRest || expr != tt.wantExpr {
+				t.Errorf("splitFilter(%q) = %q, %q; want %q, %q", tt.input, rest, expr, tt.wantRest, tt.wantExpr)
+			}
+		})
+	}
+}
+
+func TestResolveDocument(t *testing.T) {
+	t.Parallel()
+
+	m, err := trusted.Parse([]byte(`{"doc-1": "query Me { me { id } }"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &REPL{manifest: m}
+
+	listed := &client.Request{Query: "query Me {\n  me { id }\n}"}
+	if !r.resolveDocument(listed) {
+		t.Error("resolveDocument() = false for a listed operation")
+	}
+
+	if listed.DocumentID != "doc-1" {
+		t.Errorf("DocumentID = %q, want doc-1", listed.DocumentID)
+	}
+
+	unlisted := &client.Request{Query: "{ me { id name } }"}
+	if r.resolveDocument(unlisted) {
+		t.Error("resolveDocument() = true for an unlisted operation")
+	}
+
+	if unlisted.DocumentID != "" {
+		t.Errorf("DocumentID = %q for an unlisted operation", unlisted.DocumentID)
+	}
+}
@@ -0,0 +1,141 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// statement is a script statement and the line it starts on.
+type statement struct {
+	line int
+	text string
+}
+
+// RunScript executes the statements of a script file in order, stopping at
+// the first failing statement or at "exit". A response with GraphQL errors
+// fails the script.
+func (r *REPL) RunScript(path string) error {
+	f, err := os.Open(path) //nolint:gosec // script path is provided by the user
+	if err != nil {
+		return fmt.Errorf("open script: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	stmts, err := parseScript(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	for _, s := range stmts {
+		fmt.Println(gray("> " + summarizeQuery(s.text)))
+
+		lastID := r.history.lastID
+
+		if err := r.execute(s.text); err != nil {
+			if errors.Is(err, errExit) {
+				return nil
+			}
+
+			return fmt.Errorf("%s:%d: %w", path, s.line, err)
+		}
+
+		// Fail on GraphQL errors from the requests sent by this statement
+		for _, e := range r.history.since(lastID) {
+			if len(e.response.Errors) > 0 {
+				return fmt.Errorf("%s:%d: response has errors: %s", path, s.line, e.response.Errors[0].Message)
+			}
+		}
+	}
+
+	if r.batching {
+		return fmt.Errorf("%s: batch is not closed with 'end'", path)
+	}
+
+	return nil
+}
+
+// parseScript splits a script into statements. Each line is a statement,
+// except that a GraphQL operation continues until its braces are balanced.
+// Lines starting with '#' are comments.
+func parseScript(src io.Reader) ([]statement, error) {
+	var (
+		stmts []statement
+		cur   *statement
+		depth int
+	)
+
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+
+		if cur != nil {
+			cur.text += "\n" + line
+		} else {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+
+			if !isGraphQL(trimmed) {
+				stmts = append(stmts, statement{line: n, text: trimmed})
+
+				continue
+			}
+
+			cur = &statement{line: n, text: trimmed}
+			depth = 0
+			line = trimmed
+		}
+
+		depth += braceDepth(line)
+		if depth <= 0 && strings.Contains(cur.text, "{") {
+			stmts = append(stmts, *cur)
+			cur = nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+
+	if cur != nil {
+		return nil, fmt.Errorf("line %d: unterminated operation", cur.line)
+	}
+
+	return stmts, nil
+}
+
+// braceDepth returns the change in brace depth over s, ignoring string literals.
+func braceDepth(s string) int {
+	depth := 0
+	inString := false
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		}
+	}
+
+	return depth
+}
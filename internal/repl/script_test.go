@@ -0,0 +1,144 @@
+package repl
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+func TestParseScript(t *testing.T) {
+	t.Parallel()
+
+	src := `# create a user
+mutation {
+  createUser(name: "{ not a brace") { id }
+}
+let userId = .createUser.id
+
+query GetPosts($id: ID!)
+{
+  posts(authorId: $id) { title }
+} | .posts
+header set X-User {{userId}}
+`
+
+	stmts, err := parseScript(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("parseScript() error = %v", err)
+	}
+
+	want := []statement{
+		{2, "mutation {\n  createUser(name: \"{ not a brace\") { id }\n}"},
+		{5, "let userId = .createUser.id"},
+		{7, "query GetPosts($id: ID!)\n{\n  posts(authorId: $id) { title }\n} | .posts"},
+		{11, "header set X-User {{userId}}"},
+	}
+	if !reflect.DeepEqual(stmts, want) {
+		t.Errorf("parseScript() = %q\nwant %q", stmts, want)
+	}
+
+	if _, err := parseScript(strings.NewReader("{ users {\n  id\n")); err == nil {
+		t.Error("parseScript() expected error for unterminated operation")
+	}
+}
+
+func TestLetAndInterpolate(t *testing.T) {
+	t.Parallel()
+
+	r := &REPL{history: newHistory(defaultHistorySize), vars: make(map[string]json.RawMessage)}
+	r.history.add(&historyEntry{response: &client.Response{
+		Data: json.RawMessage(`{"createUser":{"id":"u-1","age":30,"tags":["a","b"]}}`),
+	}})
+
+	for _, stmt := range []string{
+		"let id = .createUser.id",
+		"let age = .createUser.age",
+		"let tags = .createUser.tags",
+	} {
+		if err := r.cmdLet(stmt); err != nil {
+			t.Fatalf("cmdLet(%q) error = %v", stmt, err)
+		}
+	}
+
+	got, err := r.interpolate(`{ user(id: "{{id}}", age: {{ age }}) { tags(in: {{tags}}) } }`)
+	if err != nil {
+		t.Fatalf("interpolate() error = %v", err)
+	}
+
+	if want := `{ user(id: "u-1", age: 30) { tags(in: ["a","b"]) } }`; got != want {
+		t.Errorf("interpolate() = %s, want %s", got, want)
+	}
+
+	if _, err := r.interpolate("{{missing}}"); err == nil {
+		t.Error("interpolate() expected error for undefined variable")
+	}
+
+	if err := r.cmdLet("let all = .createUser.tags[]"); err == nil {
+		t.Error("cmdLet() expected error for multiple values")
+	}
+}
+
+func TestInterpolate_Escape(t *testing.T) {
+	t.Parallel()
+
+	r := &REPL{vars: map[string]json.RawMessage{
+		"name": json.RawMessage(`"a\") { secret } #\nb"`),
+		"id":   json.RawMessage(`"u-1"`),
+		"doc":  json.RawMessage(`"a \"\"\" b"`),
+	}}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "inside a string",
+			input: `{ user(name: "{{name}}") { id } }`,
+			want:  `{ user(name: "a\") { secret } #\nb") { id } }`,
+		},
+		{
+			name:  "outside a string",
+			input: `{ user(name: {{name}}) { id } }`,
+			want:  `{ user(name: "a\") { secret } #\nb") { id } }`,
+		},
+		{
+			name:  "after an escaped quote",
+			input: `{ user(name: "say \"{{id}}\"", id: {{id}}) { id } }`,
+			want:  `{ user(name: "say \"u-1\"", id: "u-1") { id } }`,
+		},
+		{
+			name:  "inside a block string",
+			input: `mutation { post(body: """x {{id}} """) { id } }`,
+			want:  `mutation { post(body: """x u-1 """) { id } }`,
+		},
+		{
+			name:  "block string delimiter in the value",
+			input: `mutation { post(body: """{{doc}}""") { id } }`,
+			want:  `mutation { post(body: """a \""" b""") { id } }`,
+		},
+		{
+			name:  "command",
+			input: `header set X-User {{id}}`,
+			want:  `header set X-User u-1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := r.interpolate(tt.input)
+			if err != nil {
+				t.Fatalf("interpolate() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("interpolate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/config"
+	"github.com/sivchari/iris/internal/cookies"
+	"github.com/sivchari/iris/internal/federation"
+	"github.com/sivchari/iris/internal/gql"
+	"github.com/sivchari/iris/internal/output"
+)
+
+const schemaLoadTimeout = 30 * time.Second
+
+// cmdHeader manages the headers sent with each request.
+func (r *REPL) cmdHeader(args []string) error {
+	if len(args) == 0 {
+		return r.listHeaders()
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		return r.listHeaders()
+	case "set":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: header set <name> <value>")
+		}
+
+		key := strings.TrimSuffix(args[1], ":")
+		r.client = r.client.Clone(client.WithoutHeader(key), client.WithHeader(key, strings.Join(args[2:], " ")))
+	case "unset", "rm":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: header unset <name>")
+		}
+
+		r.client = r.client.Clone(client.WithoutHeader(args[1]))
+	default:
+		return fmt.Errorf("unknown: %s (use: set, unset, list)", args[0])
+	}
+
+	return nil
+}
+
+func (r *REPL) listHeaders() error {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	headers := r.client.Headers()
+	if len(headers) == 0 {
+		fmt.Println("No headers set.")
+
+		return nil
+	}
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	fmt.Println(cyan("Headers:"))
+
+	for _, k := range keys {
+		fmt.Printf("  %s: %s\n", yellow(k), headers[k])
+	}
+
+	return nil
+}
+
+// cmdCookies lists or clears the cookies kept by the client.
+func (r *REPL) cmdCookies(args []string) error {
+	jar, ok := r.client.CookieJar().(*cookies.Jar)
+	if !ok {
+		return fmt.Errorf("cookies are not kept (use --cookies or set cookies: true in the profile)")
+	}
+
+	if len(args) == 0 {
+		return listCookies(jar)
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		return listCookies(jar)
+	case "clear":
+		if err := jar.Clear(); err != nil {
+			return fmt.Errorf("clear cookies: %w", err)
+		}
+
+		fmt.Println("Cookies cleared.")
+	default:
+		return fmt.Errorf("unknown: %s (use: list, clear)", args[0])
+	}
+
+	return nil
+}
+
+func listCookies(jar *cookies.Jar) error {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	list := jar.List()
+	if len(list) == 0 {
+		fmt.Println("No cookies.")
+
+		return nil
+	}
+
+	fmt.Println(cyan("Cookies:"))
+
+	for _, c := range list {
+		expires := "session"
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Local().Format(time.DateTime)
+		}
+
+		fmt.Printf("  %s=%s  %s%s  (%s)\n", yellow(c.Name), c.Value, c.Domain, c.Path, expires)
+	}
+
+	if path := jar.Path(); path != "" {
+		fmt.Printf("Saved in %s\n", path)
+	}
+
+	return nil
+}
+
+// cmdUse switches to another endpoint, given as a profile name or a URL.
+func (r *REPL) cmdUse(args []string) error {
+	if len(args) == 0 {
+		return r.showProfiles()
+	}
+
+	if r.connect == nil {
+		return fmt.Errorf("switching endpoints is not available")
+	}
+
+	conn, err := r.connect(args[0])
+	if err != nil {
+		return err
+	}
+
+	return r.switchTo(conn)
+}
+
+func (r *REPL) showProfiles() error {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	fmt.Printf("%s %s\n", cyan("Endpoint:"), r.client.Endpoint())
+
+	cfg, err := config.Load(config.DefaultPaths()...)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if len(cfg.Profiles) > 0 {
+		fmt.Println(cyan("Profiles:"))
+
+		for _, name := range cfg.Names() {
+			marker := " "
+			if name == r.profile {
+				marker = green("*")
+			}
+
+			fmt.Printf(" %s %s (%s)\n", marker, name, cfg.Profiles[name].Endpoint)
+		}
+	}
+
+	fmt.Println("\nUsage: use <profile|url>")
+
+	return nil
+}
+
+// switchTo loads the schema from the new endpoint and replaces the session state.
+// The current session is kept when the schema cannot be loaded.
+func (r *REPL) switchTo(conn *Connection) error {
+	fmt.Printf("Connecting to %s...\n", conn.Client.Endpoint())
+
+	ctx, cancel := context.WithTimeout(context.Background(), schemaLoadTimeout)
+	defer cancel()
+
+	schema, err := gql.LoadSchema(ctx, conn.Client, conn.Schema)
+	if err != nil {
+		return fmt.Errorf("load schema: %w", err)
+	}
+
+	r.client = conn.Client
+	r.profile = conn.Profile
+	r.manifest = conn.Manifest
+	r.pager = nil
+
+	if conn.Output != "" {
+		r.output = conn.Output
+	}
+	r.schema = schema
+	r.federation = federation.Detect(schema)
+	r.completer = gql.NewCompleter(schema)
+
+	fmt.Printf("Loaded %d types.\n", len(schema.Types))
+
+	if r.federation != nil {
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s: %s (subgraph)\n", green("Federation"), r.federation.Provider.Name())
+	}
+
+	return nil
+}
+
+// cmdSet shows or changes session settings.
+func (r *REPL) cmdSet(args []string) error {
+	if len(args) == 0 {
+		cyan := color.New(color.FgCyan).SprintFunc()
+
+		fmt.Println(cyan("Settings:"))
+		fmt.Printf("  output: %s\n", r.output)
+		fmt.Printf("  history: %d\n", r.history.size)
+		fmt.Printf("  timing: %s\n", onOff(r.timing))
+		fmt.Printf("  trace: %s\n", onOff(r.trace))
+		fmt.Println("\nUsage: set <name> <value>")
+
+		return nil
+	}
+
+	if len(args) < 2 {
+		return fmt.Errorf("usage: set %s <value>", args[0])
+	}
+
+	switch args[0] {
+	case "output":
+		f, err := output.ParseFormat(args[1])
+		if err != nil {
+			return fmt.Errorf("set output: %w", err)
+		}
+
+		r.output = f
+	case "history":
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("set history: invalid size: %s", args[1])
+		}
+
+		r.history.size = n
+		r.history.trim()
+	case "timing":
+		on, err := parseOnOff(args[1])
+		if err != nil {
+			return fmt.Errorf("set timing: %w", err)
+		}
+
+		r.timing = on
+	case "trace":
+		on, err := parseOnOff(args[1])
+		if err != nil {
+			return fmt.Errorf("set trace: %w", err)
+		}
+
+		r.trace = on
+	default:
+		return fmt.Errorf("unknown setting: %s (use: output, history, timing, trace)", args[0])
+	}
+
+	return nil
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+
+	return "off"
+}
+
+func parseOnOff(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "on", "true", "1":
+		return true, nil
+	case "off", "false", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("expected on or off: %s", s)
+	}
+}
@@ -0,0 +1,39 @@
+package repl
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/tracing"
+)
+
+// errNoTrace is returned when a response has no tracing extensions.
+var errNoTrace = fmt.Errorf("no trace in response (ask the server for one, e.g. 'header set apollo-federation-include-trace ftv1')")
+
+// cmdTrace renders the resolver trace and query plan of a response.
+func (r *REPL) cmdTrace(args []string) error {
+	e, err := r.history.lookup(argAt(args, 0))
+	if err != nil {
+		return err
+	}
+
+	return printTrace(e.response)
+}
+
+func printTrace(resp *client.Response) error {
+	t, err := tracing.FromExtensions(resp.Extensions)
+	if err != nil {
+		return fmt.Errorf("trace: %w", err)
+	}
+
+	if t == nil {
+		return errNoTrace
+	}
+
+	if err := tracing.Render(os.Stdout, t); err != nil {
+		return fmt.Errorf("trace: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package repl
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/sivchari/iris/internal/gql"
+)
+
+// uploadScalar is the conventional name of the scalar for multipart file uploads.
+const uploadScalar = "Upload"
+
+// upload is an Upload argument, read as local file paths.
+type upload []string
+
+func isUpload(t *ast.Type) bool {
+	return gql.UnwrapType(t) == uploadScalar
+}
+
+// parseUpload reads comma-separated file paths.
+func parseUpload(input string) upload {
+	var paths upload
+
+	for p := range strings.SplitSeq(input, ",") {
+		if p = strings.Trim(strings.TrimSpace(p), "\"'"); p != "" {
+			paths = append(paths, p)
+		}
+	}
+
+	return paths
+}
+
+// uploadVariables returns the variable definitions for the Upload arguments, such as "($avatar: Upload!)".
+func uploadVariables(field *ast.FieldDefinition, args map[string]any) string {
+	var defs []string
+
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		if _, ok := args[name].(upload); !ok {
+			continue
+		}
+
+		if a := field.Arguments.ForName(name); a != nil {
+			defs = append(defs, "$"+name+": "+gql.FormatType(a.Type))
+		}
+	}
+
+	if len(defs) == 0 {
+		return ""
+	}
+
+	return "(" + strings.Join(defs, ", ") + ")"
+}
+
+// uploadFiles maps the variable paths of the Upload arguments to their files.
+// A list argument gets one path per file, such as "files.0".
+func uploadFiles(field *ast.FieldDefinition, args map[string]any) map[string]string {
+	files := make(map[string]string)
+
+	for name, v := range args {
+		paths, ok := v.(upload)
+		if !ok {
+			continue
+		}
+
+		if a := field.Arguments.ForName(name); a != nil && a.Type.Elem == nil && len(paths) > 0 {
+			files[name] = paths[0]
+
+			continue
+		}
+
+		for i, p := range paths {
+			files[name+"."+strconv.Itoa(i)] = p
+		}
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	return files
+}
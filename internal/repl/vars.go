@@ -0,0 +1,233 @@
+package repl
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/sivchari/iris/internal/filter"
+)
+
+var (
+	// letStatement matches "let <name> = <jq expression>".
+	letStatement = regexp.MustCompile(`^let\s+([A-Za-z_]\w*)\s*=\s*(.+)$`)
+	// varReference matches "{{name}}" references to session variables.
+	varReference = regexp.MustCompile(`\{\{\s*([A-Za-z_]\w*)\s*\}\}`)
+)
+
+// cmdLet captures a value from the last response: "let userId = .createUser.id".
+// Without arguments it lists the session variables.
+func (r *REPL) cmdLet(input string) error {
+	if input == "let" {
+		r.listVars()
+
+		return nil
+	}
+
+	m := letStatement.FindStringSubmatch(input)
+	if m == nil {
+		return fmt.Errorf("usage: let <name> = <jq expression>")
+	}
+
+	name, expr := m[1], m[2]
+
+	f, err := filter.Compile(expr)
+	if err != nil {
+		return err //nolint:wrapcheck // already describes the filter error
+	}
+
+	var data json.RawMessage
+	if e := r.history.last(); e != nil {
+		data = e.response.Data
+	}
+
+	if len(data) == 0 {
+		data = json.RawMessage("null")
+	}
+
+	results, err := f.Apply(data)
+	if err != nil {
+		return err //nolint:wrapcheck // already describes the filter error
+	}
+
+	if len(results) != 1 {
+		return fmt.Errorf("let %s: expression produced %d values, want 1", name, len(results))
+	}
+
+	r.vars[name] = results[0]
+
+	return nil
+}
+
+func (r *REPL) listVars() {
+	if len(r.vars) == 0 {
+		fmt.Println("No variables. Use 'let <name> = <jq expression>'.")
+
+		return
+	}
+
+	names := make([]string, 0, len(r.vars))
+	for name := range r.vars {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	for _, name := range names {
+		fmt.Printf("  %s = %s\n", cyan(name), r.vars[name])
+	}
+}
+
+// interpolate replaces "{{name}}" references with session variables.
+// Non-string values are inserted as JSON. In commands strings are inserted
+// as is; in GraphQL input they are escaped, so that a value cannot change
+// the document: a reference inside a string literal gets the escaped
+// characters, and one outside of a string a quoted string literal.
+func (r *REPL) interpolate(input string) (string, error) {
+	var missing []string
+
+	graphQL := isGraphQL(input)
+
+	out := replaceRefs(input, func(name string, offset int) (string, bool) {
+		v, ok := r.vars[name]
+		if !ok {
+			missing = append(missing, name)
+
+			return "", false
+		}
+
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			return string(v), true
+		}
+
+		if !graphQL {
+			return s, true
+		}
+
+		switch stringContext(input, offset) {
+		case inString:
+			quoted := quoteString(s)
+
+			return quoted[1 : len(quoted)-1], true
+		case inBlockString:
+			return strings.ReplaceAll(s, `"""`, `\"""`), true
+		default:
+			return quoteString(s), true
+		}
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variable: %s", strings.Join(missing, ", "))
+	}
+
+	return out, nil
+}
+
+// replaceRefs replaces each "{{name}}" reference in input with the value
+// returned by fn for its name and byte offset, or keeps it when fn returns false.
+func replaceRefs(input string, fn func(name string, offset int) (string, bool)) string {
+	var b strings.Builder
+
+	last := 0
+
+	for _, m := range varReference.FindAllStringSubmatchIndex(input, -1) {
+		b.WriteString(input[last:m[0]])
+
+		if v, ok := fn(input[m[2]:m[3]], m[0]); ok {
+			b.WriteString(v)
+		} else {
+			b.WriteString(input[m[0]:m[1]])
+		}
+
+		last = m[1]
+	}
+
+	b.WriteString(input[last:])
+
+	return b.String()
+}
+
+type lexicalContext int
+
+const (
+	outsideString lexicalContext = iota
+	inString
+	inBlockString
+)
+
+// stringContext reports whether offset in the GraphQL source src is inside
+// a string literal, a block string or neither.
+func stringContext(src string, offset int) lexicalContext {
+	ctx := outsideString
+
+	for i := 0; i < offset; i++ {
+		var skip int
+
+		ctx, skip = nextContext(ctx, src[i:])
+		i += skip
+	}
+
+	return ctx
+}
+
+// nextContext returns the context after the first character of rest, and how
+// many more characters belong to the same token.
+func nextContext(ctx lexicalContext, rest string) (lexicalContext, int) {
+	switch ctx {
+	case inBlockString:
+		if strings.HasPrefix(rest, `\"""`) {
+			return inBlockString, 3
+		}
+
+		if strings.HasPrefix(rest, `"""`) {
+			return outsideString, 2
+		}
+	case inString:
+		if rest[0] == '\\' {
+			return inString, 1
+		}
+
+		if rest[0] == '"' {
+			return outsideString, 0
+		}
+	case outsideString:
+		if strings.HasPrefix(rest, `"""`) {
+			return inBlockString, 2
+		}
+
+		if rest[0] == '"' {
+			return inString, 0
+		}
+
+		// Comments run to the end of the line
+		if rest[0] == '#' {
+			if n := strings.IndexByte(rest, '\n'); n >= 0 {
+				return outsideString, n
+			}
+
+			return outsideString, len(rest)
+		}
+	}
+
+	return ctx, 0
+}
+
+// quoteString returns s as a GraphQL string literal, whose escapes are the
+// same as JSON's.
+func quoteString(s string) string {
+	var b strings.Builder
+
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+
+	// Encoding a string cannot fail
+	_ = enc.Encode(s)
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
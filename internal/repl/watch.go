@@ -0,0 +1,77 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/watch"
+)
+
+// cmdWatch re-runs the last request, or the query in a file, until Ctrl+C.
+// Watched runs are not recorded in the history.
+func (r *REPL) cmdWatch(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: watch <interval> [file]")
+	}
+
+	interval, err := time.ParseDuration(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid interval: %s", args[0])
+	}
+
+	path := argAt(args, 1)
+
+	var req *client.Request
+
+	title := path
+
+	if path == "" {
+		e := r.history.last()
+		if e == nil {
+			return fmt.Errorf("nothing to watch: send a query first or give a file")
+		}
+
+		req, title = e.request, summarizeQuery(e.request.Query)
+	}
+
+	w := &watch.Watcher{
+		Title:    title,
+		Interval: interval,
+		Execute: func(ctx context.Context) (*client.Response, error) {
+			if path != "" {
+				data, err := os.ReadFile(path) //nolint:gosec // path is provided by the user
+				if err != nil {
+					return nil, fmt.Errorf("read query: %w", err)
+				}
+
+				req = &client.Request{Query: string(data)}
+			}
+
+			resp, err := r.client.Execute(ctx, req)
+			if err != nil {
+				return nil, fmt.Errorf("execute: %w", err)
+			}
+
+			return resp, nil
+		},
+		Print: r.printResponse,
+	}
+
+	if path != "" {
+		w.Files = []string{path}
+	}
+
+	// The prompt leaves the terminal in cooked mode while a command runs, so Ctrl+C arrives as SIGINT
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := w.Run(ctx); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package sigv4
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Credentials are AWS access keys.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials.
+	SessionToken string
+}
+
+// LoadCredentials reads credentials from the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables, or else
+// from a profile of the shared credentials file. An empty profile means
+// AWS_PROFILE, or "default".
+func LoadCredentials(profile string) (Credentials, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" && profile == "" {
+		return Credentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return Credentials{}, fmt.Errorf("sigv4: no credentials in the environment and no home directory: %w", err)
+		}
+
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+
+	return loadFile(path, cmp.Or(profile, os.Getenv("AWS_PROFILE"), "default"))
+}
+
+// loadFile reads a profile of a shared credentials file.
+func loadFile(path, profile string) (Credentials, error) {
+	f, err := os.Open(path) //nolint:gosec // credentials path is trusted
+	if err != nil {
+		return Credentials{}, fmt.Errorf("sigv4: no credentials in the environment: %w", err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	var (
+		creds   Credentials
+		section string
+		found   bool
+	)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			found = found || section == profile
+
+			continue
+		case section != profile:
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Credentials{}, fmt.Errorf("sigv4: read %s: %w", path, err)
+	}
+
+	if !found {
+		return Credentials{}, fmt.Errorf("sigv4: profile %s not found in %s", profile, path)
+	}
+
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("sigv4: profile %s has no aws_access_key_id or aws_secret_access_key", profile)
+	}
+
+	return creds, nil
+}
@@ -0,0 +1,266 @@
+// Package sigv4 signs HTTP requests with AWS Signature Version 4, for
+// GraphQL APIs behind IAM auth such as AWS AppSync and API Gateway.
+package sigv4
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+const (
+	algorithm  = "AWS4-HMAC-SHA256"
+	timeFormat = "20060102T150405Z"
+	dateFormat = "20060102"
+)
+
+// Signer signs requests for a service in a region.
+type Signer struct {
+	Credentials Credentials
+	Region      string
+	Service     string
+
+	now func() time.Time
+}
+
+// New creates a signer. Empty region and service are inferred from the host
+// of each request, as in abc.appsync-api.us-east-1.amazonaws.com.
+func New(creds Credentials, region, service string) *Signer {
+	return &Signer{Credentials: creds, Region: region, Service: service, now: time.Now}
+}
+
+// Middleware returns a client middleware that signs each request just
+// before it is sent, so that retries are signed again.
+func (s *Signer) Middleware() client.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return client.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			body, err := readBody(req)
+			if err != nil {
+				return nil, err
+			}
+
+			// RoundTrippers must not modify the caller's request
+			req = req.Clone(req.Context())
+			if body != nil {
+				req.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			if err := s.Sign(req, body, s.now()); err != nil {
+				return nil, err
+			}
+
+			return next.RoundTrip(req) //nolint:wrapcheck // middleware passes errors through
+		})
+	}
+}
+
+// readBody returns the request body without consuming it.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	rc := req.Body
+	if req.GetBody != nil {
+		var err error
+		if rc, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("sigv4: read body: %w", err)
+		}
+	}
+
+	defer func() { _ = rc.Close() }()
+
+	body, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("sigv4: read body: %w", err)
+	}
+
+	return body, nil
+}
+
+// Sign adds the X-Amz-Date, X-Amz-Security-Token and Authorization headers
+// for body, the request payload, signed at t.
+func (s *Signer) Sign(req *http.Request, body []byte, t time.Time) error {
+	region, service := s.Region, s.Service
+	if region == "" || service == "" {
+		r, svc := Infer(req.URL.Hostname())
+		region, service = cmp.Or(region, r), cmp.Or(service, svc)
+	}
+
+	if region == "" || service == "" {
+		return fmt.Errorf("sigv4: cannot infer the region and service of %s (set them explicitly)", req.URL.Host)
+	}
+
+	t = t.UTC()
+	amzDate := t.Format(timeFormat)
+	scope := strings.Join([]string{t.Format(dateFormat), region, service, "aws4_request"}, "/")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	if s.Credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.Credentials.SessionToken)
+	}
+
+	headers, signed := canonicalHeaders(req)
+
+	canonical := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL),
+		headers,
+		signed,
+		hexSHA256(body),
+	}, "\n")
+
+	toSign := strings.Join([]string{algorithm, amzDate, scope, hexSHA256([]byte(canonical))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.Credentials.SecretAccessKey), t.Format(dateFormat))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, s.Credentials.AccessKeyID, scope, signed, signature))
+
+	return nil
+}
+
+// Infer returns the region and service of an AWS host name: appsync for
+// AppSync GraphQL APIs, execute-api for API Gateway, or the label before the
+// region for other amazonaws.com hosts. It returns empty strings for other hosts.
+func Infer(host string) (region, service string) {
+	labels := strings.Split(strings.TrimSuffix(host, ".amazonaws.com"), ".")
+	if len(labels) < 2 || !strings.HasSuffix(host, ".amazonaws.com") {
+		return "", ""
+	}
+
+	region = labels[len(labels)-1]
+	service = labels[len(labels)-2]
+
+	if service == "appsync-api" {
+		service = "appsync"
+	}
+
+	return region, service
+}
+
+// canonicalHeaders returns the canonical headers block and the signed header
+// list. Host, Content-Type and the X-Amz-* headers are signed; headers the
+// transport may still change are left out.
+func canonicalHeaders(req *http.Request) (string, string) {
+	values := map[string]string{"host": req.Host}
+	if values["host"] == "" {
+		values["host"] = req.URL.Host
+	}
+
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		if name != "content-type" && !strings.HasPrefix(name, "x-amz-") {
+			continue
+		}
+
+		trimmed := make([]string, len(v))
+		for i, s := range v {
+			trimmed[i] = strings.Join(strings.Fields(s), " ")
+		}
+
+		values[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + values[name] + "\n")
+	}
+
+	return b.String(), strings.Join(names, ";")
+}
+
+func canonicalPath(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(u.Path, "/")
+	for i, s := range segments {
+		segments[i] = escape(s)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(u *url.URL) string {
+	params := u.Query()
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return escape(keys[i]) < escape(keys[j]) })
+
+	var pairs []string
+
+	for _, k := range keys {
+		values := make([]string, len(params[k]))
+		for i, v := range params[k] {
+			values[i] = escape(v)
+		}
+
+		sort.Strings(values)
+
+		for _, v := range values {
+			pairs = append(pairs, escape(k)+"="+v)
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// escape percent-encodes everything except the RFC 3986 unreserved characters.
+func escape(s string) string {
+	var b strings.Builder
+
+	for i := range len(s) {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,182 @@
+package sigv4
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+// exampleCreds and exampleTime are the inputs of the AWS SigV4 test suite.
+var (
+	exampleCreds = Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	exampleTime  = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+)
+
+func TestSign_TestSuite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   string
+	}{
+		{"get-vanilla", http.MethodGet, "https://example.amazonaws.com/", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"post-vanilla", http.MethodPost, "https://example.amazonaws.com/", "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b"},
+		{"get-vanilla-query-order-key-case", http.MethodGet, "https://example.amazonaws.com/?Param2=value2&Param1=value1", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Header = http.Header{}
+
+			s := New(exampleCreds, "us-east-1", "service")
+			if err := s.Sign(req, nil, exampleTime); err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+
+			want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=" + tt.want
+			if got := req.Header.Get("Authorization"); got != want {
+				t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+			}
+
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q", got)
+			}
+		})
+	}
+}
+
+func TestInfer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		host, region, service string
+	}{
+		{"abc123.appsync-api.eu-west-1.amazonaws.com", "eu-west-1", "appsync"},
+		{"abc123.execute-api.us-east-1.amazonaws.com", "us-east-1", "execute-api"},
+		{"api.example.com", "", ""},
+		{"amazonaws.com", "", ""},
+	}
+
+	for _, tt := range tests {
+		region, service := Infer(tt.host)
+		if region != tt.region || service != tt.service {
+			t.Errorf("Infer(%q) = %q, %q, want %q, %q", tt.host, region, service, tt.region, tt.service)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var (
+		auth, token, body string
+		signedAt          string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		token = r.Header.Get("X-Amz-Security-Token")
+		signedAt = r.Header.Get("X-Amz-Date")
+
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+
+		_, _ = io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+
+	creds := exampleCreds
+	creds.SessionToken = "session"
+
+	s := New(creds, "us-east-1", "appsync")
+	s.now = func() time.Time { return exampleTime }
+
+	c := client.New(srv.URL, client.WithMiddleware(s.Middleware()))
+	if _, err := c.Execute(context.Background(), &client.Request{Query: "{ ok }"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if !strings.Contains(body, "{ ok }") {
+		t.Errorf("body = %q, want the query after signing", body)
+	}
+
+	if token != "session" || signedAt != "20150830T123600Z" {
+		t.Errorf("X-Amz-Security-Token = %q, X-Amz-Date = %q", token, signedAt)
+	}
+
+	// The server recomputes the signature from what it received
+	req := httptest.NewRequest(http.MethodPost, srv.URL, nil)
+	req.Header = http.Header{"Content-Type": {"application/json"}}
+
+	if err := s.Sign(req, []byte(body), exampleTime); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	if want := req.Header.Get("Authorization"); auth != want {
+		t.Errorf("Authorization =\n%s\nwant\n%s", auth, want)
+	}
+
+	if !strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Authorization = %s, want content-type and the session token signed", auth)
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+
+	err := os.WriteFile(path, []byte(`
+[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = default-secret
+
+# a comment
+[staging]
+aws_access_key_id=AKIDSTAGING
+aws_secret_access_key=staging-secret
+aws_session_token=staging-token
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_PROFILE", "")
+
+	creds, err := LoadCredentials("")
+	if err != nil || creds.AccessKeyID != "AKIDDEFAULT" || creds.SecretAccessKey != "default-secret" {
+		t.Errorf("LoadCredentials(\"\") = %+v, %v", creds, err)
+	}
+
+	creds, err = LoadCredentials("staging")
+	if err != nil || creds.AccessKeyID != "AKIDSTAGING" || creds.SessionToken != "staging-token" {
+		t.Errorf("LoadCredentials(staging) = %+v, %v", creds, err)
+	}
+
+	if _, err := LoadCredentials("missing"); err == nil || !strings.Contains(err.Error(), "profile missing not found") {
+		t.Errorf("LoadCredentials(missing) error = %v", err)
+	}
+
+	// The environment wins when no profile is asked for
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+
+	creds, err = LoadCredentials("")
+	if err != nil || creds.AccessKeyID != "AKIDENV" {
+		t.Errorf("LoadCredentials(\"\") with env = %+v, %v", creds, err)
+	}
+}
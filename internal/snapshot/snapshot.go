@@ -0,0 +1,273 @@
+// Package snapshot records normalized GraphQL responses to golden files and
+// verifies later responses against them.
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/filter"
+	"github.com/sivchari/iris/internal/jsondiff"
+	"github.com/sivchari/iris/internal/testsuite"
+)
+
+// Dir is the directory, next to each test file, that holds its snapshots.
+const Dir = "__snapshots__"
+
+// Masked replaces masked values in snapshots.
+const Masked = "<masked>"
+
+// Status is the outcome of recording or verifying a snapshot.
+type Status string
+
+// Snapshot statuses.
+const (
+	Created   Status = "created"
+	Updated   Status = "updated"
+	Unchanged Status = "unchanged"
+	Matched   Status = "matched"
+	Drifted   Status = "drifted"
+	Missing   Status = "missing"
+	Failed    Status = "failed"
+)
+
+// Result is the outcome for a single test.
+type Result struct {
+	File   string
+	Name   string
+	Path   string
+	Status Status
+	// Changes lists the differences from the golden file when Status is Drifted.
+	Changes []jsondiff.Change
+	Err     error
+}
+
+// OK reports whether the snapshot was recorded or matched.
+func (r *Result) OK() bool {
+	switch r.Status {
+	case Drifted, Missing, Failed:
+		return false
+	default:
+		return true
+	}
+}
+
+// Options configures recording and verification.
+type Options struct {
+	// Mask lists paths masked in every snapshot, in addition to those of the test files.
+	Mask []string
+}
+
+// Record runs the tests and writes their normalized responses to golden files.
+func Record(ctx context.Context, c *client.Client, files []*testsuite.File, opts Options, onResult func(*Result)) []*Result {
+	return each(ctx, c, files, opts, onResult, record)
+}
+
+// Verify runs the tests and compares their normalized responses to the golden files.
+func Verify(ctx context.Context, c *client.Client, files []*testsuite.File, opts Options, onResult func(*Result)) []*Result {
+	return each(ctx, c, files, opts, onResult, verify)
+}
+
+func each(
+	ctx context.Context,
+	c *client.Client,
+	files []*testsuite.File,
+	opts Options,
+	onResult func(*Result),
+	fn func(res *Result, current []byte),
+) []*Result {
+	var results []*Result
+
+	// Tests whose names have the same slug would share a golden file
+	owners := make(map[string]string)
+
+	for _, f := range files {
+		for _, tc := range f.Tests {
+			res := &Result{File: f.Path, Name: tc.Name, Path: Path(f.Path, tc.Name)}
+
+			if owner, ok := owners[res.Path]; ok {
+				res.Status = Failed
+				res.Err = fmt.Errorf("snapshot %s is also used by test %q; rename one of them", res.Path, owner)
+			} else {
+				owners[res.Path] = tc.Name
+
+				current, err := snapshotCase(ctx, c, tc, concat(opts.Mask, f.Mask, tc.Mask))
+				if err != nil {
+					res.Status, res.Err = Failed, err
+				} else {
+					fn(res, current)
+				}
+			}
+
+			results = append(results, res)
+
+			if onResult != nil {
+				onResult(res)
+			}
+		}
+	}
+
+	return results
+}
+
+func record(res *Result, current []byte) {
+	golden, err := os.ReadFile(res.Path)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		res.Status = Created
+	case err != nil:
+		res.Status, res.Err = Failed, fmt.Errorf("read snapshot: %w", err)
+
+		return
+	case bytes.Equal(golden, current):
+		res.Status = Unchanged
+
+		return
+	default:
+		res.Status = Updated
+	}
+
+	// Golden files are meant to be committed, so they are world-readable
+	if err := os.MkdirAll(filepath.Dir(res.Path), 0o755); err != nil { //nolint:gosec // see above
+		res.Status, res.Err = Failed, fmt.Errorf("create snapshot dir: %w", err)
+
+		return
+	}
+
+	if err := os.WriteFile(res.Path, current, 0o644); err != nil { //nolint:gosec // see above
+		res.Status, res.Err = Failed, fmt.Errorf("write snapshot: %w", err)
+	}
+}
+
+func verify(res *Result, current []byte) {
+	golden, err := os.ReadFile(res.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		res.Status = Missing
+
+		return
+	}
+
+	if err != nil {
+		res.Status, res.Err = Failed, fmt.Errorf("read snapshot: %w", err)
+
+		return
+	}
+
+	changes, err := jsondiff.Compare(golden, current)
+	if err != nil {
+		res.Status, res.Err = Failed, fmt.Errorf("compare: %w", err)
+
+		return
+	}
+
+	res.Status, res.Changes = Matched, changes
+	if len(changes) > 0 {
+		res.Status = Drifted
+	}
+}
+
+func snapshotCase(ctx context.Context, c *client.Client, tc *testsuite.Case, mask []string) ([]byte, error) {
+	resp, err := tc.Execute(ctx, c)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // already wrapped by Execute
+	}
+
+	return Normalize(resp, mask)
+}
+
+// Normalize renders the data and errors of a response as indented JSON.
+// Object keys in the data are sorted and the values at the mask paths are
+// replaced with Masked.
+func Normalize(resp *client.Response, mask []string) ([]byte, error) {
+	f, err := filter.Mask(mask, Masked)
+	if err != nil {
+		return nil, fmt.Errorf("mask: %w", err)
+	}
+
+	data := resp.Data
+	if len(data) == 0 {
+		data = json.RawMessage("null")
+	}
+
+	masked, err := f.Apply(data)
+	if err != nil {
+		return nil, fmt.Errorf("mask: %w", err)
+	}
+
+	if len(masked) != 1 {
+		return nil, fmt.Errorf("mask: produced %d values", len(masked))
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []client.Error  `json:"errors,omitempty"`
+	}{masked[0], resp.Errors}); err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Path returns the golden file of a test: __snapshots__/<file>/<test>.json
+// next to the test file.
+func Path(testFile, name string) string {
+	stem := strings.TrimSuffix(filepath.Base(testFile), filepath.Ext(testFile))
+
+	return filepath.Join(filepath.Dir(testFile), Dir, stem, slug(name)+".json")
+}
+
+// slug turns a test name into a file name. Names without letters or digits
+// get a hash of the name instead.
+func slug(name string) string {
+	var b strings.Builder
+
+	dash := false
+
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+
+			dash = true
+		}
+	}
+
+	if s := strings.TrimSuffix(b.String(), "-"); s != "" {
+		return s
+	}
+
+	sum := sha256.Sum256([]byte(name))
+
+	return "test-" + hex.EncodeToString(sum[:4])
+}
+
+func concat(lists ...[]string) []string {
+	var out []string
+
+	for _, l := range lists {
+		out = append(out, l...)
+	}
+
+	return out
+}
@@ -0,0 +1,168 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/testsuite"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	resp := &client.Response{
+		Data:   json.RawMessage(`{"user":{"name":"Alice","id":"42","createdAt":"2024-05-01T10:00:00Z"}}`),
+		Errors: []client.Error{{Message: "partial", Path: []any{"user", "posts"}}},
+	}
+
+	got, err := Normalize(resp, []string{"$.user.id", ".user.createdAt"})
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+
+	want := `{
+  "data": {
+    "user": {
+      "createdAt": "<masked>",
+      "id": "<masked>",
+      "name": "Alice"
+    }
+  },
+  "errors": [
+    {
+      "message": "partial",
+      "path": [
+        "user",
+        "posts"
+      ]
+    }
+  ]
+}
+`
+	if string(got) != want {
+		t.Errorf("Normalize() = %s\nwant %s", got, want)
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Parallel()
+
+	testFile := filepath.Join("tests", "users.yaml")
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Fetch a user (by ID)", want: "fetch-a-user-by-id.json"},
+		{name: "???", want: "test-" + hexPrefix("???") + ".json"},
+		{name: "!!!", want: "test-" + hexPrefix("!!!") + ".json"},
+	}
+
+	for _, tt := range tests {
+		if got, want := Path(testFile, tt.name), filepath.Join("tests", Dir, "users", tt.want); got != want {
+			t.Errorf("Path(%q) = %s, want %s", tt.name, got, want)
+		}
+	}
+}
+
+func hexPrefix(s string) string {
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:4])
+}
+
+func TestRecord_DuplicatePath(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"ok":true}}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "users.yaml")
+
+	src := "tests:\n  - name: Get user\n    query: '{ ok }'\n  - name: get-user\n    query: '{ ok }'\n"
+	if err := os.WriteFile(testFile, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := testsuite.Load(testFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	res := Record(context.Background(), client.New(srv.URL), files, Options{}, nil)
+	if len(res) != 2 {
+		t.Fatalf("Record() returned %d results, want 2", len(res))
+	}
+
+	if res[0].Status != Created {
+		t.Errorf("first test = %s, want %s", res[0].Status, Created)
+	}
+
+	if res[1].Status != Failed || res[1].Err == nil || !strings.Contains(res[1].Err.Error(), `also used by test "Get user"`) {
+		t.Errorf("second test = %s, %v, want a duplicate path error", res[1].Status, res[1].Err)
+	}
+}
+
+func TestRecordVerify(t *testing.T) {
+	t.Parallel()
+
+	var name atomic.Value
+
+	name.Store("Alice")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"user":{"name":"` + name.Load().(string) + `","requestId":"r-1"}}}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "users.yaml")
+
+	if err := os.WriteFile(testFile, []byte("mask: [.user.requestId]\ntests:\n  - name: user\n    query: '{ user { name } }'\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := testsuite.Load(testFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	c := client.New(srv.URL)
+	ctx := context.Background()
+
+	if res := Verify(ctx, c, files, Options{}, nil); res[0].Status != Missing {
+		t.Errorf("Verify() before record = %s, want %s", res[0].Status, Missing)
+	}
+
+	if res := Record(ctx, c, files, Options{}, nil); res[0].Status != Created || res[0].Err != nil {
+		t.Fatalf("Record() = %s, %v", res[0].Status, res[0].Err)
+	}
+
+	if res := Record(ctx, c, files, Options{}, nil); res[0].Status != Unchanged {
+		t.Errorf("Record() again = %s, want %s", res[0].Status, Unchanged)
+	}
+
+	if res := Verify(ctx, c, files, Options{}, nil); res[0].Status != Matched {
+		t.Errorf("Verify() = %s, want %s", res[0].Status, Matched)
+	}
+
+	name.Store("Bob")
+
+	res := Verify(ctx, c, files, Options{}, nil)
+	if res[0].Status != Drifted || len(res[0].Changes) != 1 || res[0].Changes[0].Path != ".data.user.name" {
+		t.Errorf("Verify() after drift = %s, %+v", res[0].Status, res[0].Changes)
+	}
+}
@@ -0,0 +1,97 @@
+package testsuite
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML, with one test suite per file.
+func WriteJUnit(w io.Writer, r *Report) error {
+	out := junitTestSuites{Time: seconds(r.Duration.Seconds())}
+
+	index := make(map[string]int)
+
+	for _, res := range r.Results {
+		i, ok := index[res.File]
+		if !ok {
+			i = len(out.Suites)
+			index[res.File] = i
+			out.Suites = append(out.Suites, junitTestSuite{Name: res.File})
+		}
+
+		suite := &out.Suites[i]
+		tc := junitTestCase{
+			Name:      res.Name,
+			Classname: res.File,
+			Time:      seconds(res.Duration.Seconds()),
+		}
+
+		switch {
+		case res.Err != nil:
+			tc.Error = &junitMessage{Message: res.Err.Error(), Body: res.Err.Error()}
+			suite.Errors++
+			out.Errors++
+		case len(res.Failures) > 0:
+			tc.Failure = &junitMessage{Message: res.Failures[0], Body: strings.Join(res.Failures, "\n")}
+			suite.Failures++
+			out.Failures++
+		}
+
+		suite.Tests++
+		out.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
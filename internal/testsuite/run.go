@@ -0,0 +1,248 @@
+package testsuite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/filter"
+	"github.com/sivchari/iris/internal/jsondiff"
+)
+
+// Result is the outcome of a test.
+type Result struct {
+	File     string
+	Name     string
+	Duration time.Duration
+	// Failures describe the failed assertions.
+	Failures []string
+	// Err is set when the request could not be executed.
+	Err error
+}
+
+// Passed reports whether the test passed.
+func (r *Result) Passed() bool {
+	return r.Err == nil && len(r.Failures) == 0
+}
+
+// Report is the outcome of a test run.
+type Report struct {
+	Results  []*Result
+	Duration time.Duration
+}
+
+// Failed returns the number of failed tests.
+func (r *Report) Failed() int {
+	n := 0
+
+	for _, res := range r.Results {
+		if !res.Passed() {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Run executes the tests in order. onResult, if not nil, is called after each test.
+func Run(ctx context.Context, c *client.Client, files []*File, onResult func(*Result)) *Report {
+	start := time.Now()
+	report := &Report{}
+
+	for _, f := range files {
+		for _, tc := range f.Tests {
+			res := runCase(ctx, c, f.Path, tc)
+			report.Results = append(report.Results, res)
+
+			if onResult != nil {
+				onResult(res)
+			}
+		}
+	}
+
+	report.Duration = time.Since(start)
+
+	return report
+}
+
+// Execute sends the operation of the test with its headers added to c.
+func (tc *Case) Execute(ctx context.Context, c *client.Client) (*client.Response, error) {
+	if len(tc.Headers) > 0 {
+		opts := make([]client.Option, 0, len(tc.Headers))
+		for k, v := range tc.Headers {
+			opts = append(opts, client.WithHeader(k, v))
+		}
+
+		c = c.Clone(opts...)
+	}
+
+	resp, err := c.Execute(ctx, &client.Request{
+		Query:         tc.Query,
+		Variables:     tc.Variables,
+		OperationName: tc.OperationName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("execute: %w", err)
+	}
+
+	return resp, nil
+}
+
+func runCase(ctx context.Context, c *client.Client, path string, tc *Case) *Result {
+	res := &Result{File: path, Name: tc.Name}
+
+	start := time.Now()
+
+	resp, err := tc.Execute(ctx, c)
+
+	res.Duration = time.Since(start)
+
+	if err != nil {
+		res.Err = err
+
+		return res
+	}
+
+	if resp.Timing != nil {
+		res.Duration = resp.Timing.Total
+	}
+
+	res.Failures = check(&tc.Expect, resp, res.Duration)
+
+	return res
+}
+
+// check evaluates the expectations against a response and returns the failures.
+func check(exp *Expect, resp *client.Response, latency time.Duration) []string {
+	var failures []string
+
+	if exp.ErrorCode != "" {
+		failures = append(failures, checkErrorCode(exp.ErrorCode, resp.Errors)...)
+	} else if len(resp.Errors) > 0 {
+		msgs := make([]string, 0, len(resp.Errors))
+		for _, e := range resp.Errors {
+			msgs = append(msgs, e.Message)
+		}
+
+		failures = append(failures, "unexpected errors: "+strings.Join(msgs, "; "))
+	}
+
+	data := resp.Data
+	if len(data) == 0 {
+		data = json.RawMessage("null")
+	}
+
+	if exp.Data != nil {
+		failures = append(failures, checkSubset(exp.Data, data)...)
+	}
+
+	exprs := make([]string, 0, len(exp.Paths))
+	for expr := range exp.Paths {
+		exprs = append(exprs, expr)
+	}
+
+	sort.Strings(exprs)
+
+	for _, expr := range exprs {
+		failures = append(failures, checkPath(expr, exp.Paths[expr], data)...)
+	}
+
+	if exp.MaxLatency > 0 && latency > exp.MaxLatency {
+		failures = append(failures, fmt.Sprintf("latency %s exceeds %s", latency.Round(time.Millisecond), exp.MaxLatency))
+	}
+
+	return failures
+}
+
+func checkErrorCode(code string, errs []client.Error) []string {
+	codes := make([]string, 0, len(errs))
+
+	for _, e := range errs {
+		if e.Code() == code {
+			return nil
+		}
+
+		codes = append(codes, e.Code())
+	}
+
+	if len(errs) == 0 {
+		return []string{fmt.Sprintf("expected error code %s, got no errors", code)}
+	}
+
+	return []string{fmt.Sprintf("expected error code %s, got %s", code, strings.Join(codes, ", "))}
+}
+
+// checkSubset reports the differences between want and data, ignoring
+// object keys and array elements that only exist in data.
+func checkSubset(want any, data []byte) []string {
+	expected, err := json.Marshal(want)
+	if err != nil {
+		return []string{fmt.Sprintf("encode expected data: %v", err)}
+	}
+
+	changes, err := jsondiff.Compare(expected, data)
+	if err != nil {
+		return []string{fmt.Sprintf("compare data: %v", err)}
+	}
+
+	var failures []string
+
+	for _, c := range changes {
+		switch c.Kind {
+		case jsondiff.Added:
+			// Extra fields in the response are allowed
+		case jsondiff.Removed:
+			failures = append(failures, fmt.Sprintf("data%s: missing, want %s", pathSuffix(c.Path), jsondiff.Format(c.Old)))
+		case jsondiff.Changed:
+			failures = append(failures, fmt.Sprintf("data%s: got %s, want %s", pathSuffix(c.Path), jsondiff.Format(c.New), jsondiff.Format(c.Old)))
+		}
+	}
+
+	return failures
+}
+
+// checkPath evaluates a jq expression or JSONPath and compares its single result to want.
+func checkPath(expr string, want any, data []byte) []string {
+	f, err := filter.Compile(expr)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	results, err := f.Apply(data)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	if len(results) != 1 {
+		return []string{fmt.Sprintf("%s: produced %d values, want 1", expr, len(results))}
+	}
+
+	expected, err := json.Marshal(want)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: encode expected value: %v", expr, err)}
+	}
+
+	changes, err := jsondiff.Compare(expected, results[0])
+	if err != nil {
+		return []string{fmt.Sprintf("%s: compare: %v", expr, err)}
+	}
+
+	if len(changes) > 0 {
+		return []string{fmt.Sprintf("%s: got %s, want %s", expr, results[0], expected)}
+	}
+
+	return nil
+}
+
+// pathSuffix turns the root path "." into "" so that it can follow "data".
+func pathSuffix(path string) string {
+	if path == "." {
+		return ""
+	}
+
+	return path
+}
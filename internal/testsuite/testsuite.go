@@ -0,0 +1,162 @@
+// Package testsuite runs assertion-based GraphQL tests described in YAML files.
+package testsuite
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is a test file.
+type File struct {
+	Path  string  `yaml:"-"`
+	Tests []*Case `yaml:"tests"`
+	// Mask lists paths of volatile values masked in snapshots of every test.
+	Mask []string `yaml:"mask"`
+}
+
+// Case is a single test: an operation and the expectations on its response.
+type Case struct {
+	Name string `yaml:"name"`
+	// Query is the GraphQL document. QueryFile, relative to the test file, may be used instead.
+	Query         string            `yaml:"query"`
+	QueryFile     string            `yaml:"queryFile"`
+	Variables     map[string]any    `yaml:"variables"`
+	OperationName string            `yaml:"operationName"`
+	Headers       map[string]string `yaml:"headers"`
+	Expect        Expect            `yaml:"expect"`
+	// Mask lists paths of volatile values masked in the snapshot of this test.
+	Mask []string `yaml:"mask"`
+}
+
+// Expect lists the assertions of a test. A response must not contain errors
+// unless ErrorCode is set.
+type Expect struct {
+	// Data must be a subset of the response data: objects may have extra keys
+	// and arrays extra trailing elements.
+	Data any `yaml:"data"`
+	// Paths maps jq expressions or JSONPaths to the value they must produce.
+	Paths map[string]any `yaml:"paths"`
+	// ErrorCode is the extensions.code one of the errors must have.
+	ErrorCode  string        `yaml:"errorCode"`
+	MaxLatency time.Duration `yaml:"maxLatency"`
+}
+
+// Load reads the test files matched by the patterns. A pattern is a YAML file,
+// a directory (its *.yaml and *.yml files) or a directory followed by "/..."
+// to include subdirectories.
+func Load(patterns ...string) ([]*File, error) {
+	var paths []string
+
+	for _, pattern := range patterns {
+		matched, err := expand(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, matched...)
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no test files found in %s", strings.Join(patterns, ", "))
+	}
+
+	files := make([]*File, 0, len(paths))
+
+	for _, path := range paths {
+		f, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+// LoadFile reads a single test file.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // test path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	f := &File{Path: path}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	for i, c := range f.Tests {
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("test %d", i+1)
+		}
+
+		if c.QueryFile != "" {
+			q, err := os.ReadFile(filepath.Join(filepath.Dir(path), c.QueryFile)) //nolint:gosec // relative to the test file
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: read query: %w", path, c.Name, err)
+			}
+
+			c.Query = string(q)
+		}
+
+		if strings.TrimSpace(c.Query) == "" {
+			return nil, fmt.Errorf("%s: %s: no query", path, c.Name)
+		}
+	}
+
+	return f, nil
+}
+
+func expand(pattern string) ([]string, error) {
+	dir, recursive := strings.CutSuffix(pattern, "/...")
+	if pattern == "..." {
+		dir, recursive = ".", true
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: %w", dir, err)
+	}
+
+	if !info.IsDir() {
+		return []string{dir}, nil
+	}
+
+	var paths []string
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != dir && !recursive {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.SkipDir) {
+		return nil, fmt.Errorf("walk %s: %w", dir, err)
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
@@ -0,0 +1,159 @@
+package testsuite
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+const usersTests = `tests:
+  - name: fetch a user
+    queryFile: user.graphql
+    variables: { id: "1" }
+    expect:
+      data: { user: { name: Alice, tags: [admin] } }
+      paths:
+        "$.user.id": "1"
+        ".user.tags | length": 2
+      maxLatency: 1m
+  - name: wrong name
+    query: "{ user { name } }"
+    expect:
+      data: { user: { name: Bob, email: bob@example.com } }
+  - name: requires auth
+    query: "{ me { id } }"
+    expect:
+      errorCode: UNAUTHENTICATED
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "users.yaml"), usersTests)
+	writeFile(t, filepath.Join(dir, "user.graphql"), "query GetUser($id: ID!) { user(id: $id) { id name tags } }")
+	writeFile(t, filepath.Join(dir, "nested", "more.yml"), "tests:\n  - query: '{ a }'\n")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	files, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(files) != 1 || len(files[0].Tests) != 3 {
+		t.Fatalf("Load(dir) = %d files, want 1 with 3 tests", len(files))
+	}
+
+	if !strings.HasPrefix(files[0].Tests[0].Query, "query GetUser") {
+		t.Errorf("queryFile not loaded: %q", files[0].Tests[0].Query)
+	}
+
+	files, err = Load(dir + "/...")
+	if err != nil {
+		t.Fatalf("Load(...) error = %v", err)
+	}
+
+	if len(files) != 2 || files[0].Tests[0].Name != "test 1" {
+		t.Errorf("Load(dir/...) = %d files, want 2", len(files))
+	}
+
+	if _, err := Load(filepath.Join(dir, "nested", "missing")); err == nil {
+		t.Error("Load() expected error for missing path")
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(r.Body)
+
+		if strings.Contains(body.String(), "{ me ") {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"not signed in","extensions":{"code":"UNAUTHENTICATED"}}]}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"user":{"id":"1","name":"Alice","tags":["admin","dev"]}}}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "users.yaml"), usersTests)
+	writeFile(t, filepath.Join(dir, "user.graphql"), "query GetUser($id: ID!) { user(id: $id) { id name tags } }")
+
+	files, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	report := Run(context.Background(), client.New(srv.URL), files, nil)
+
+	if got := report.Failed(); got != 1 {
+		t.Fatalf("Failed() = %d, want 1", got)
+	}
+
+	want := []string{
+		`data.user.email: missing, want "bob@example.com"`,
+		`data.user.name: got "Alice", want "Bob"`,
+	}
+	if got := report.Results[1].Failures; !reflect.DeepEqual(got, want) {
+		t.Errorf("Failures = %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, report); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	for _, s := range []string{
+		`<testsuites tests="3" failures="1" errors="0"`,
+		`<testcase name="wrong name"`,
+		`<failure message="data.user.email: missing, want &#34;bob@example.com&#34;">`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("JUnit report should contain %s\n%s", s, buf.String())
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	resp := &client.Response{Errors: []client.Error{{Message: "boom", Extensions: map[string]any{"code": "INTERNAL"}}}}
+
+	got := check(&Expect{ErrorCode: "NOT_FOUND", Paths: map[string]any{".x": 1}}, resp, 0)
+	want := []string{
+		"expected error code NOT_FOUND, got INTERNAL",
+		".x: got null, want 1",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("check() = %q, want %q", got, want)
+	}
+
+	if got := check(&Expect{}, resp, 0); len(got) != 1 || got[0] != "unexpected errors: boom" {
+		t.Errorf("check() = %q", got)
+	}
+}
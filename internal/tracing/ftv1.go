@@ -0,0 +1,350 @@
+package tracing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the Trace message in Apollo's reports.proto.
+const (
+	traceEndTime    = 3
+	traceStartTime  = 4
+	traceDurationNs = 11
+	traceRoot       = 14
+	traceQueryPlan  = 26
+
+	nodeResponseName      = 1
+	nodeIndex             = 2
+	nodeType              = 3
+	nodeStartTime         = 8
+	nodeEndTime           = 9
+	nodeChild             = 12
+	nodeParentType        = 13
+	nodeOriginalFieldName = 14
+
+	planSequence  = 1
+	planParallel  = 2
+	planFetch     = 3
+	planFlatten   = 4
+	planDefer     = 5
+	planCondition = 6
+
+	planChildNodes       = 1
+	fetchServiceName     = 1
+	fetchTrace           = 3
+	flattenResponsePath  = 1
+	flattenNode          = 2
+	pathElementFieldName = 1
+	pathElementIndex     = 2
+
+	timestampSeconds = 1
+	timestampNanos   = 2
+)
+
+// DecodeFTV1 decodes a protobuf-encoded Apollo federated trace (Trace message).
+func DecodeFTV1(data []byte) (*Trace, error) {
+	trace := &Trace{}
+
+	var start, end time.Time
+
+	var root []byte
+
+	err := eachField(data, func(f wireField) error {
+		var err error
+
+		switch f.num {
+		case traceDurationNs:
+			trace.Duration = time.Duration(f.varint)
+		case traceStartTime:
+			start, err = decodeTimestamp(f.bytes)
+		case traceEndTime:
+			end, err = decodeTimestamp(f.bytes)
+		case traceRoot:
+			root = f.bytes
+		case traceQueryPlan:
+			trace.Plan, err = decodePlan(f.bytes)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if trace.Duration == 0 && !start.IsZero() && !end.IsZero() {
+		trace.Duration = end.Sub(start)
+	}
+
+	if root != nil {
+		if err := decodeNode(root, "", &trace.Spans); err != nil {
+			return nil, err
+		}
+	}
+
+	sortSpans(trace.Spans)
+
+	return trace, nil
+}
+
+func decodeNode(data []byte, parentPath string, spans *[]Span) error {
+	var (
+		responseName, fieldName, returnType, parentType string
+		index                                           uint64
+		hasIndex                                        bool
+		start, end                                      uint64
+		children                                        [][]byte
+	)
+
+	err := eachField(data, func(f wireField) error {
+		switch f.num {
+		case nodeResponseName:
+			responseName = string(f.bytes)
+		case nodeIndex:
+			index, hasIndex = f.varint, true
+		case nodeType:
+			returnType = string(f.bytes)
+		case nodeParentType:
+			parentType = string(f.bytes)
+		case nodeOriginalFieldName:
+			fieldName = string(f.bytes)
+		case nodeStartTime:
+			start = f.varint
+		case nodeEndTime:
+			end = f.varint
+		case nodeChild:
+			children = append(children, f.bytes)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	path := parentPath
+
+	switch {
+	case responseName != "":
+		path = appendPath(parentPath, responseName)
+
+		if fieldName == "" {
+			fieldName = responseName
+		}
+
+		*spans = append(*spans, Span{
+			Path:       path,
+			ParentType: parentType,
+			FieldName:  fieldName,
+			ReturnType: returnType,
+			Start:      time.Duration(start),
+			Duration:   time.Duration(end - min(start, end)),
+		})
+	case hasIndex:
+		path = appendPath(parentPath, strconv.FormatUint(index, 10))
+	}
+
+	for _, child := range children {
+		if err := decodeNode(child, path, spans); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodePlan(data []byte) (*PlanNode, error) {
+	var node *PlanNode
+
+	err := eachField(data, func(f wireField) error {
+		var err error
+
+		switch f.num {
+		case planSequence:
+			node, err = decodePlanChildren("Sequence", f.bytes)
+		case planParallel:
+			node, err = decodePlanChildren("Parallel", f.bytes)
+		case planFetch:
+			node, err = decodeFetch(f.bytes)
+		case planFlatten:
+			node, err = decodeFlatten(f.bytes)
+		case planDefer:
+			node = &PlanNode{Kind: "Defer"}
+		case planCondition:
+			node = &PlanNode{Kind: "Condition"}
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if node == nil {
+		return nil, fmt.Errorf("empty query plan node")
+	}
+
+	return node, nil
+}
+
+func decodePlanChildren(kind string, data []byte) (*PlanNode, error) {
+	node := &PlanNode{Kind: kind}
+
+	err := eachField(data, func(f wireField) error {
+		if f.num != planChildNodes {
+			return nil
+		}
+
+		child, err := decodePlan(f.bytes)
+		if err != nil {
+			return err
+		}
+
+		node.Nodes = append(node.Nodes, child)
+
+		return nil
+	})
+
+	return node, err
+}
+
+func decodeFetch(data []byte) (*PlanNode, error) {
+	node := &PlanNode{Kind: "Fetch"}
+
+	err := eachField(data, func(f wireField) error {
+		var err error
+
+		switch f.num {
+		case fetchServiceName:
+			node.Service = string(f.bytes)
+		case fetchTrace:
+			node.Trace, err = DecodeFTV1(f.bytes)
+		}
+
+		return err
+	})
+
+	return node, err
+}
+
+func decodeFlatten(data []byte) (*PlanNode, error) {
+	node := &PlanNode{Kind: "Flatten"}
+
+	var path []string
+
+	err := eachField(data, func(f wireField) error {
+		switch f.num {
+		case flattenResponsePath:
+			elem, err := decodePathElement(f.bytes)
+			if err != nil {
+				return err
+			}
+
+			path = append(path, elem)
+		case flattenNode:
+			child, err := decodePlan(f.bytes)
+			if err != nil {
+				return err
+			}
+
+			node.Nodes = append(node.Nodes, child)
+		}
+
+		return nil
+	})
+
+	node.Path = strings.Join(path, ".")
+
+	return node, err
+}
+
+func decodePathElement(data []byte) (string, error) {
+	// A list element without a field name flattens every item, shown as @
+	elem := "@"
+
+	err := eachField(data, func(f wireField) error {
+		switch f.num {
+		case pathElementFieldName:
+			elem = string(f.bytes)
+		case pathElementIndex:
+			elem = strconv.FormatUint(f.varint, 10)
+		}
+
+		return nil
+	})
+
+	return elem, err
+}
+
+func decodeTimestamp(data []byte) (time.Time, error) {
+	var seconds, nanos uint64
+
+	err := eachField(data, func(f wireField) error {
+		switch f.num {
+		case timestampSeconds:
+			seconds = f.varint
+		case timestampNanos:
+			nanos = f.varint
+		}
+
+		return nil
+	})
+
+	return time.Unix(int64(seconds), int64(nanos)), err //nolint:gosec // protobuf int64 fields are varint-encoded
+}
+
+func appendPath(parent, elem string) string {
+	if parent == "" {
+		return elem
+	}
+
+	return parent + "." + elem
+}
+
+// wireField is a decoded protobuf field. Only varint and length-delimited values are kept.
+type wireField struct {
+	num    protowire.Number
+	varint uint64
+	bytes  []byte
+}
+
+// eachField calls fn for every varint and length-delimited field in data.
+func eachField(data []byte, fn func(wireField) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("protobuf: %w", protowire.ParseError(n))
+		}
+
+		data = data[n:]
+		f := wireField{num: num}
+
+		switch typ {
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+
+		if n < 0 {
+			return fmt.Errorf("protobuf: %w", protowire.ParseError(n))
+		}
+
+		data = data[n:]
+
+		if typ != protowire.VarintType && typ != protowire.BytesType {
+			continue
+		}
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	barWidth = 30
+	maxSpans = 50
+)
+
+// Render writes a per-resolver timing waterfall to w. When the trace has a
+// query plan, subgraph fetches are shown as a tree with their own waterfalls.
+func Render(w io.Writer, t *Trace) error {
+	var sb strings.Builder
+
+	if len(t.Spans) > 0 {
+		fmt.Fprintf(&sb, "Resolvers (%s)\n", formatDuration(traceDuration(t)))
+		renderSpans(&sb, t, "  ")
+	}
+
+	if t.Plan != nil {
+		sb.WriteString("Query plan\n")
+		renderPlan(&sb, t.Plan, "  ")
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+func renderPlan(sb *strings.Builder, n *PlanNode, indent string) {
+	switch n.Kind {
+	case "Fetch":
+		fmt.Fprintf(sb, "%sFetch %s", indent, n.Service)
+
+		if n.Trace != nil {
+			fmt.Fprintf(sb, " (%s)", formatDuration(traceDuration(n.Trace)))
+		}
+
+		sb.WriteString("\n")
+
+		if n.Trace != nil {
+			renderSpans(sb, n.Trace, indent+"  ")
+		}
+	case "Flatten":
+		fmt.Fprintf(sb, "%sFlatten %s\n", indent, n.Path)
+	default:
+		fmt.Fprintf(sb, "%s%s\n", indent, n.Kind)
+	}
+
+	for _, child := range n.Nodes {
+		renderPlan(sb, child, indent+"  ")
+	}
+}
+
+func renderSpans(sb *strings.Builder, t *Trace, indent string) {
+	total := traceDuration(t)
+
+	tw := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+
+	for i, s := range t.Spans {
+		if i == maxSpans {
+			fmt.Fprintf(tw, "%s... %d more\n", indent, len(t.Spans)-maxSpans)
+
+			break
+		}
+
+		field := s.FieldName
+		if s.ParentType != "" {
+			field = s.ParentType + "." + field
+		}
+
+		if s.ReturnType != "" {
+			field += ": " + s.ReturnType
+		}
+
+		fmt.Fprintf(tw, "%s%s\t%s\t|%s|\t%s\n",
+			indent, s.Path, field, bar(s.Start, s.Duration, total), formatDuration(s.Duration))
+	}
+
+	_ = tw.Flush()
+}
+
+// traceDuration returns the trace duration, or the end of the last span when it is not reported.
+func traceDuration(t *Trace) time.Duration {
+	d := t.Duration
+
+	for _, s := range t.Spans {
+		d = max(d, s.Start+s.Duration)
+	}
+
+	return d
+}
+
+func bar(start, d, total time.Duration) string {
+	if total <= 0 {
+		return strings.Repeat(" ", barWidth)
+	}
+
+	from := min(int(int64(barWidth)*int64(start)/int64(total)), barWidth-1)
+	n := min(max(int(int64(barWidth)*int64(d)/int64(total)), 1), barWidth-from)
+
+	return strings.Repeat(" ", from) + strings.Repeat("█", n) + strings.Repeat(" ", barWidth-from-n)
+}
+
+func formatDuration(d time.Duration) string {
+	if d >= time.Millisecond {
+		return d.Round(10 * time.Microsecond).String()
+	}
+
+	return d.Round(time.Microsecond).String()
+}
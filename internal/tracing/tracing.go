@@ -0,0 +1,234 @@
+// Package tracing decodes resolver traces and federated query plans from
+// GraphQL response extensions.
+//
+// Supported extensions are Apollo tracing v1 ("tracing"), the Apollo
+// federated trace protobuf ("ftv1") and query plans exposed by Apollo
+// Router and Gateway ("apolloQueryPlan", "queryPlan").
+package tracing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Trace is the resolver timing of one operation.
+type Trace struct {
+	Duration time.Duration
+	Spans    []Span
+	// Plan is the federated query plan, if any.
+	Plan *PlanNode
+}
+
+// Span is the execution of a single resolver.
+type Span struct {
+	// Path is the response path, such as users.0.email.
+	Path       string
+	ParentType string
+	FieldName  string
+	ReturnType string
+	// Start is the offset from the start of the operation.
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// PlanNode is a node of a federated query plan.
+type PlanNode struct {
+	// Kind is Sequence, Parallel, Fetch, Flatten or another plan node kind.
+	Kind string
+	// Service is the subgraph name of a Fetch node.
+	Service string
+	// Path is the response path of a Flatten node.
+	Path  string
+	Nodes []*PlanNode
+	// Trace is the subgraph trace of a Fetch node, when reported.
+	Trace *Trace
+}
+
+// FromExtensions decodes a trace from response extensions.
+// It returns nil when the extensions contain no trace or query plan.
+func FromExtensions(ext map[string]json.RawMessage) (*Trace, error) {
+	var trace *Trace
+
+	var err error
+
+	switch {
+	case ext["ftv1"] != nil:
+		trace, err = decodeFTV1Extension(ext["ftv1"])
+	case ext["tracing"] != nil:
+		trace, err = decodeTracingV1(ext["tracing"])
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range []string{"apolloQueryPlan", "queryPlan"} {
+		if ext[key] == nil {
+			continue
+		}
+
+		plan, err := decodeJSONPlan(ext[key])
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", key, err)
+		}
+
+		if trace == nil {
+			trace = &Trace{}
+		}
+
+		if trace.Plan == nil {
+			trace.Plan = plan
+		}
+
+		break
+	}
+
+	return trace, nil
+}
+
+func decodeFTV1Extension(raw json.RawMessage) (*Trace, error) {
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err != nil {
+		return nil, fmt.Errorf("decode ftv1: %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode ftv1: %w", err)
+	}
+
+	trace, err := DecodeFTV1(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode ftv1: %w", err)
+	}
+
+	return trace, nil
+}
+
+// tracingV1 is the Apollo tracing v1 extension format.
+type tracingV1 struct {
+	Version   int   `json:"version"`
+	Duration  int64 `json:"duration"`
+	Execution struct {
+		Resolvers []struct {
+			Path        []any  `json:"path"`
+			ParentType  string `json:"parentType"`
+			FieldName   string `json:"fieldName"`
+			ReturnType  string `json:"returnType"`
+			StartOffset int64  `json:"startOffset"`
+			Duration    int64  `json:"duration"`
+		} `json:"resolvers"`
+	} `json:"execution"`
+}
+
+func decodeTracingV1(raw json.RawMessage) (*Trace, error) {
+	var v tracingV1
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, fmt.Errorf("decode tracing: %w", err)
+	}
+
+	if v.Version != 1 {
+		return nil, fmt.Errorf("decode tracing: unsupported version %d", v.Version)
+	}
+
+	trace := &Trace{Duration: time.Duration(v.Duration)}
+
+	for _, r := range v.Execution.Resolvers {
+		trace.Spans = append(trace.Spans, Span{
+			Path:       joinPath(r.Path),
+			ParentType: r.ParentType,
+			FieldName:  r.FieldName,
+			ReturnType: r.ReturnType,
+			Start:      time.Duration(r.StartOffset),
+			Duration:   time.Duration(r.Duration),
+		})
+	}
+
+	sortSpans(trace.Spans)
+
+	return trace, nil
+}
+
+// jsonPlanNode is a query plan node in the JSON form exposed by Apollo Router and Gateway.
+type jsonPlanNode struct {
+	Kind        string          `json:"kind"`
+	ServiceName string          `json:"serviceName"`
+	Path        []any           `json:"path"`
+	Node        *jsonPlanNode   `json:"node"`
+	Nodes       []*jsonPlanNode `json:"nodes"`
+}
+
+func decodeJSONPlan(raw json.RawMessage) (*PlanNode, error) {
+	// Apollo Router wraps the plan as {"object": {"kind": "QueryPlan", "node": ...}, "text": ...}
+	var wrapped struct {
+		Object *jsonPlanNode `json:"object"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by caller
+	}
+
+	root := wrapped.Object
+	if root == nil {
+		root = &jsonPlanNode{}
+		if err := json.Unmarshal(raw, root); err != nil {
+			return nil, err //nolint:wrapcheck // wrapped by caller
+		}
+	}
+
+	if root.Kind == "QueryPlan" {
+		root = root.Node
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("empty query plan")
+	}
+
+	return convertJSONPlan(root), nil
+}
+
+func convertJSONPlan(n *jsonPlanNode) *PlanNode {
+	node := &PlanNode{
+		Kind:    n.Kind,
+		Service: n.ServiceName,
+		Path:    joinPath(n.Path),
+	}
+
+	for _, child := range n.Nodes {
+		node.Nodes = append(node.Nodes, convertJSONPlan(child))
+	}
+
+	if n.Node != nil {
+		node.Nodes = append(node.Nodes, convertJSONPlan(n.Node))
+	}
+
+	return node
+}
+
+// joinPath formats a response path such as ["users", 0, "email"] as users.0.email.
+func joinPath(path []any) string {
+	parts := make([]string, 0, len(path))
+
+	for _, p := range path {
+		switch v := p.(type) {
+		case string:
+			parts = append(parts, v)
+		case float64:
+			parts = append(parts, strconv.Itoa(int(v)))
+		default:
+			parts = append(parts, fmt.Sprint(v))
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func sortSpans(spans []Span) {
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Start < spans[j].Start
+	})
+}
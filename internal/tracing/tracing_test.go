@@ -0,0 +1,203 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+
+	return protowire.AppendBytes(b, v)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+
+	return protowire.AppendVarint(b, v)
+}
+
+func fieldNode(name, parent, typ string, start, end uint64, children ...[]byte) []byte {
+	var b []byte
+
+	b = appendBytes(b, nodeResponseName, []byte(name))
+	b = appendBytes(b, nodeType, []byte(typ))
+	b = appendBytes(b, nodeParentType, []byte(parent))
+	b = appendVarint(b, nodeStartTime, start)
+	b = appendVarint(b, nodeEndTime, end)
+
+	for _, c := range children {
+		b = appendBytes(b, nodeChild, c)
+	}
+
+	return b
+}
+
+func indexNode(i uint64, children ...[]byte) []byte {
+	b := appendVarint(nil, nodeIndex, i)
+
+	for _, c := range children {
+		b = appendBytes(b, nodeChild, c)
+	}
+
+	return b
+}
+
+func testFTV1() []byte {
+	email := fieldNode("email", "User", "String!", 3_000_000, 4_000_000)
+	users := fieldNode("users", "Query", "[User!]!", 1_000_000, 2_000_000, indexNode(0, email))
+	root := appendBytes(nil, nodeChild, users)
+
+	var trace []byte
+
+	trace = appendVarint(trace, traceDurationNs, 5_000_000)
+	trace = appendBytes(trace, traceRoot, root)
+
+	return trace
+}
+
+func TestDecodeFTV1(t *testing.T) {
+	t.Parallel()
+
+	trace, err := DecodeFTV1(testFTV1())
+	if err != nil {
+		t.Fatalf("DecodeFTV1() error = %v", err)
+	}
+
+	if trace.Duration != 5*time.Millisecond {
+		t.Errorf("Duration = %v, want 5ms", trace.Duration)
+	}
+
+	want := []Span{
+		{Path: "users", ParentType: "Query", FieldName: "users", ReturnType: "[User!]!", Start: time.Millisecond, Duration: time.Millisecond},
+		{Path: "users.0.email", ParentType: "User", FieldName: "email", ReturnType: "String!", Start: 3 * time.Millisecond, Duration: time.Millisecond},
+	}
+
+	if len(trace.Spans) != len(want) {
+		t.Fatalf("Spans = %+v, want %+v", trace.Spans, want)
+	}
+
+	for i := range want {
+		if trace.Spans[i] != want[i] {
+			t.Errorf("Spans[%d] = %+v, want %+v", i, trace.Spans[i], want[i])
+		}
+	}
+}
+
+func TestDecodeFTV1_QueryPlan(t *testing.T) {
+	t.Parallel()
+
+	fetch := appendBytes(nil, fetchServiceName, []byte("accounts"))
+	fetch = appendBytes(fetch, fetchTrace, testFTV1())
+
+	pathAll := appendBytes(nil, pathElementFieldName, []byte("users"))
+	flatten := appendBytes(nil, flattenResponsePath, pathAll)
+	flatten = appendBytes(flatten, flattenResponsePath, nil)
+	flatten = appendBytes(flatten, flattenNode, appendBytes(nil, planFetch, appendBytes(nil, fetchServiceName, []byte("products"))))
+
+	seq := appendBytes(nil, planChildNodes, appendBytes(nil, planFetch, fetch))
+	seq = appendBytes(seq, planChildNodes, appendBytes(nil, planFlatten, flatten))
+
+	trace, err := DecodeFTV1(appendBytes(nil, traceQueryPlan, appendBytes(nil, planSequence, seq)))
+	if err != nil {
+		t.Fatalf("DecodeFTV1() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, trace); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"Query plan\n  Sequence\n    Fetch accounts (5ms)\n",
+		"      users.0.email  User.email: String!",
+		"    Flatten users.@\n      Fetch products\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() = %q, should contain %q", got, want)
+		}
+	}
+}
+
+func TestFromExtensions(t *testing.T) {
+	t.Parallel()
+
+	ftv1, _ := json.Marshal(base64.StdEncoding.EncodeToString(testFTV1()))
+
+	tests := []struct {
+		name      string
+		ext       map[string]json.RawMessage
+		wantSpans int
+		wantPlan  string
+	}{
+		{
+			name: "none",
+			ext:  map[string]json.RawMessage{"cost": json.RawMessage(`1`)},
+		},
+		{
+			name:      "ftv1",
+			ext:       map[string]json.RawMessage{"ftv1": ftv1},
+			wantSpans: 2,
+		},
+		{
+			name: "tracing v1",
+			ext: map[string]json.RawMessage{"tracing": json.RawMessage(`{
+				"version": 1, "duration": 2000000,
+				"execution": {"resolvers": [
+					{"path": ["me", "name"], "parentType": "User", "fieldName": "name", "returnType": "String", "startOffset": 900, "duration": 100},
+					{"path": ["me"], "parentType": "Query", "fieldName": "me", "returnType": "User", "startOffset": 100, "duration": 500}
+				]}
+			}`)},
+			wantSpans: 2,
+		},
+		{
+			name: "router query plan",
+			ext: map[string]json.RawMessage{"apolloQueryPlan": json.RawMessage(`{
+				"object": {"kind": "QueryPlan", "node": {"kind": "Parallel", "nodes": [
+					{"kind": "Fetch", "serviceName": "accounts"},
+					{"kind": "Flatten", "path": ["me", "@"], "node": {"kind": "Fetch", "serviceName": "reviews"}}
+				]}},
+				"text": "QueryPlan { ... }"
+			}`)},
+			wantPlan: "Parallel",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			trace, err := FromExtensions(tt.ext)
+			if err != nil {
+				t.Fatalf("FromExtensions() error = %v", err)
+			}
+
+			if tt.wantSpans == 0 && tt.wantPlan == "" {
+				if trace != nil {
+					t.Errorf("FromExtensions() = %+v, want nil", trace)
+				}
+
+				return
+			}
+
+			if len(trace.Spans) != tt.wantSpans {
+				t.Errorf("len(Spans) = %d, want %d", len(trace.Spans), tt.wantSpans)
+			}
+
+			if tt.wantSpans > 1 && trace.Spans[0].Start > trace.Spans[1].Start {
+				t.Errorf("Spans not sorted by start: %+v", trace.Spans)
+			}
+
+			if tt.wantPlan != "" && (trace.Plan == nil || trace.Plan.Kind != tt.wantPlan) {
+				t.Errorf("Plan = %+v, want kind %s", trace.Plan, tt.wantPlan)
+			}
+		})
+	}
+}
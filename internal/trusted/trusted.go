@@ -0,0 +1,119 @@
+// Package trusted loads persisted document manifests, which list the only
+// operations a gateway accepts, and resolves operations to document ids.
+package trusted
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// apolloFormat is the format field of an Apollo persisted query manifest.
+const apolloFormat = "apollo-persisted-query-manifest"
+
+// Manifest maps operation documents to their ids.
+type Manifest struct {
+	// ids is keyed by the normalized document
+	ids map[string]string
+}
+
+// apolloManifest is an Apollo persisted-query-manifest.json file.
+type apolloManifest struct {
+	Format     string `json:"format"`
+	Version    int    `json:"version"`
+	Operations []struct {
+		ID   string `json:"id"`
+		Body string `json:"body"`
+	} `json:"operations"`
+}
+
+// Load reads a manifest file.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	m, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// Parse reads an Apollo persisted query manifest, or a Relay or GraphQL Hive
+// manifest, which is a JSON object from document ids to documents.
+func Parse(data []byte) (*Manifest, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	m := &Manifest{ids: make(map[string]string, len(raw))}
+
+	if _, ok := raw["operations"]; ok {
+		var apollo apolloManifest
+		if err := json.Unmarshal(data, &apollo); err != nil {
+			return nil, fmt.Errorf("invalid Apollo manifest: %w", err)
+		}
+
+		if apollo.Format != "" && apollo.Format != apolloFormat {
+			return nil, fmt.Errorf("unsupported manifest format %q", apollo.Format)
+		}
+
+		for _, op := range apollo.Operations {
+			m.add(op.ID, op.Body)
+		}
+
+		return m, nil
+	}
+
+	for id, v := range raw {
+		var body string
+		if err := json.Unmarshal(v, &body); err != nil {
+			return nil, fmt.Errorf("document %s is not a string", id)
+		}
+
+		m.add(id, body)
+	}
+
+	return m, nil
+}
+
+func (m *Manifest) add(id, body string) {
+	m.ids[normalize(body)] = id
+}
+
+// Len returns the number of documents in the manifest.
+func (m *Manifest) Len() int {
+	return len(m.ids)
+}
+
+// Lookup returns the id of the document for query, ignoring formatting and comments.
+func (m *Manifest) Lookup(query string) (string, bool) {
+	id, ok := m.ids[normalize(query)]
+
+	return id, ok
+}
+
+// normalize prints a document in a canonical form, so that documents that
+// differ only in whitespace or comments compare equal.
+func normalize(doc string) string {
+	parsed, err := parser.ParseQuery(&ast.Source{Input: doc})
+	if err != nil {
+		return strings.Join(strings.Fields(doc), " ")
+	}
+
+	var buf bytes.Buffer
+
+	formatter.NewFormatter(&buf).FormatQueryDocument(parsed)
+
+	return buf.String()
+}
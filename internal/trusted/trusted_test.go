@@ -0,0 +1,92 @@
+package trusted
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "apollo",
+			data: `{
+  "format": "apollo-persisted-query-manifest",
+  "version": 1,
+  "operations": [
+    {"id": "abc", "name": "User", "type": "query", "body": "query User($id: ID!) {\n  user(id: $id) {\n    id\n  }\n}"}
+  ]
+}`,
+		},
+		{
+			name: "relay",
+			data: `{"abc": "query User($id: ID!) { user(id: $id) { id } }"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if m.Len() != 1 {
+				t.Errorf("Len() = %d, want 1", m.Len())
+			}
+
+			// Whitespace and comments do not matter
+			id, ok := m.Lookup("# typed in the REPL\nquery User($id: ID!) { user(id: $id) { id } }")
+			if !ok || id != "abc" {
+				t.Errorf("Lookup() = %q, %v, want abc", id, ok)
+			}
+
+			if _, ok := m.Lookup("query User($id: ID!) { user(id: $id) { id name } }"); ok {
+				t.Error("Lookup() found a different document")
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	for _, data := range []string{
+		`[]`,
+		`{"format": "other", "operations": []}`,
+		`{"abc": 1}`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%s) expected error", data)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "persisted-query-manifest.json")
+	if err := os.WriteFile(path, []byte(`{"operations": [{"id": "1", "body": "{ me { id } }"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if id, ok := m.Lookup("{me{id}}"); !ok || id != "1" {
+		t.Errorf("Lookup() = %q, %v", id, ok)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Load() expected error for a missing file")
+	}
+}
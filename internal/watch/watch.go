@@ -0,0 +1,193 @@
+// Package watch re-runs a GraphQL request on an interval or when files change,
+// highlighting what changed in the response since the previous run.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/sivchari/iris/internal/client"
+	"github.com/sivchari/iris/internal/jsondiff"
+)
+
+// PollInterval is how often watched files are checked for changes.
+const PollInterval = 250 * time.Millisecond
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// Watcher re-runs a request and prints each response.
+type Watcher struct {
+	// Title describes the request in the header.
+	Title string
+	// Interval between runs. Zero runs only when a file changes.
+	Interval time.Duration
+	// Files trigger a run when their modification time changes.
+	Files []string
+	// Execute sends the request. It is called for every run, so it may re-read the files.
+	Execute func(ctx context.Context) (*client.Response, error)
+	// Print writes a response.
+	Print func(resp *client.Response) error
+	// Out receives the header, errors and changes. It defaults to os.Stdout.
+	Out io.Writer
+
+	prev []byte
+	runs int
+}
+
+// Run executes immediately and then on every tick or file change until ctx is canceled.
+func (w *Watcher) Run(ctx context.Context) error {
+	if w.Out == nil {
+		w.Out = os.Stdout
+	}
+
+	if w.Interval <= 0 && len(w.Files) == 0 {
+		return fmt.Errorf("watch needs an interval or a file")
+	}
+
+	var tick <-chan time.Time
+
+	if w.Interval > 0 {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	poll := time.NewTicker(PollInterval)
+	defer poll.Stop()
+
+	mtimes := modTimes(w.Files)
+
+	w.once(ctx, "start")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick:
+			w.once(ctx, "interval")
+		case <-poll.C:
+			current := modTimes(w.Files)
+			if changed := changedFile(mtimes, current); changed != "" {
+				mtimes = current
+				w.once(ctx, changed+" changed")
+			}
+		}
+	}
+}
+
+func (w *Watcher) once(ctx context.Context, reason string) {
+	w.runs++
+
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	fmt.Fprint(w.Out, clearScreen)
+	fmt.Fprintf(w.Out, "%s  %s\n", w.header(), gray(fmt.Sprintf("%s  run #%d (%s)", time.Now().Format(time.TimeOnly), w.runs, reason)))
+	fmt.Fprintln(w.Out, gray("Press Ctrl+C to stop."))
+	fmt.Fprintln(w.Out)
+
+	resp, err := w.Execute(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintln(w.Out, red("Error:"), err)
+		}
+
+		return
+	}
+
+	if err := w.Print(resp); err != nil {
+		fmt.Fprintln(w.Out, red("Error:"), err)
+	}
+
+	w.printChanges(resp)
+}
+
+func (w *Watcher) header() string {
+	if w.Interval > 0 {
+		return fmt.Sprintf("Every %s: %s", w.Interval, w.Title)
+	}
+
+	return "On change: " + w.Title
+}
+
+// printChanges lists the differences from the previous response.
+func (w *Watcher) printChanges(resp *client.Response) {
+	cur := []byte(resp.Data)
+	if len(cur) == 0 {
+		cur = []byte("null")
+	}
+
+	prev := w.prev
+	w.prev = cur
+
+	if prev == nil {
+		return
+	}
+
+	changes, err := jsondiff.Compare(prev, cur)
+	if err != nil {
+		return
+	}
+
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Fprintln(w.Out)
+
+	if len(changes) == 0 {
+		fmt.Fprintln(w.Out, gray("No changes since the previous run."))
+
+		return
+	}
+
+	fmt.Fprintln(w.Out, yellow("Changes since the previous run:"))
+
+	for _, c := range changes {
+		switch c.Kind {
+		case jsondiff.Added:
+			fmt.Fprintln(w.Out, green("+ "+c.Path+": "+jsondiff.Format(c.New)))
+		case jsondiff.Removed:
+			fmt.Fprintln(w.Out, red("- "+c.Path+": "+jsondiff.Format(c.Old)))
+		case jsondiff.Changed:
+			fmt.Fprintf(w.Out, "%s %s: %s -> %s\n", yellow("~"), c.Path, red(jsondiff.Format(c.Old)), green(jsondiff.Format(c.New)))
+		}
+	}
+}
+
+func modTimes(files []string) map[string]time.Time {
+	mtimes := make(map[string]time.Time, len(files))
+
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			mtimes[f] = info.ModTime()
+		}
+	}
+
+	return mtimes
+}
+
+// changedFile returns the first file whose modification time differs, or "".
+func changedFile(before, after map[string]time.Time) string {
+	for f, t := range after {
+		if !before[f].Equal(t) {
+			return f
+		}
+	}
+
+	for f := range before {
+		if _, ok := after[f]; !ok {
+			return f
+		}
+	}
+
+	return ""
+}
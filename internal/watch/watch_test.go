@@ -0,0 +1,110 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sivchari/iris/internal/client"
+)
+
+// syncBuffer is a bytes.Buffer safe for the watcher and the test goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestWatcher_FileChange(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "op.graphql")
+	if err := os.WriteFile(path, []byte("1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out syncBuffer
+
+	runs := make(chan struct{}, 10)
+	w := &Watcher{
+		Title: path,
+		Files: []string{path},
+		Out:   &out,
+		Execute: func(context.Context) (*client.Response, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+
+			return &client.Response{Data: json.RawMessage(`{"value":` + string(data) + `}`)}, nil
+		},
+		Print: func(*client.Response) error {
+			runs <- struct{}{}
+
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- w.Run(ctx) }()
+
+	<-runs
+
+	// Make sure the modification time differs on coarse file systems
+	later := time.Now().Add(time.Second)
+	if err := os.WriteFile(path, []byte("2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-runs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("file change did not trigger a run")
+	}
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{"On change: " + path, "run #2 (" + path + " changed)", "Changes since the previous run:", "~ .value: 1 -> 2"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output should contain %q\n%s", want, got)
+		}
+	}
+}
+
+func TestWatcher_NeedsTrigger(t *testing.T) {
+	t.Parallel()
+
+	if err := (&Watcher{}).Run(context.Background()); err == nil {
+		t.Error("Run() expected error without interval or files")
+	}
+}